@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// errUsage is the error we return when we have already told the user
+// how to call the command, so there is nothing more to report.
+var errUsage = errors.New("usage") //nolint:gochecknoglobals // a sentinel error
+
+// newFlagSet creates a flag set for a command that prints its usage
+// to stderr.
+func newFlagSet(name, args string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: gostr %s [flags] %s\n", name, args)
+		fs.PrintDefaults()
+	}
+
+	return fs
+}
+
+// parseFlags parses the arguments and checks that we got between
+// minArgs and maxArgs positional arguments.
+func parseFlags(fs *flag.FlagSet, args []string, minArgs, maxArgs int) error {
+	if err := fs.Parse(args); err != nil {
+		return errUsage // the flag set has already reported the problem
+	}
+
+	if fs.NArg() < minArgs || fs.NArg() > maxArgs {
+		fs.Usage()
+		return errUsage
+	}
+
+	return nil
+}
+
+// openInput opens the named file, or returns stdin if the name is
+// empty or "-".
+func openInput(name string, stdin io.Reader) (io.ReadCloser, error) {
+	if name == "" || name == "-" {
+		return io.NopCloser(stdin), nil
+	}
+
+	return os.Open(name)
+}
+
+// readText reads all of the named input (see openInput) as a single
+// string, without a trailing newline.
+func readText(name string, stdin io.Reader) (string, error) {
+	in, err := openInput(name, stdin)
+	if err != nil {
+		return "", err
+	}
+
+	defer in.Close()
+
+	text, err := io.ReadAll(in)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(string(text), "\r\n"), nil
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"github.com/mailund/gostr/gostr"
+)
+
+// An index file holds a sequence collection followed by the tables for
+// searching in it. The layout, with all integers little-endian, is:
+//
+//	magic     "GOSTRIDX"
+//	version   uint32
+//	separator uint32 (the collection's Sep byte)
+//	records   uint64, then for each record:
+//	            name length uint64, name bytes, start uint64
+//	sequence  length uint64, then the bytes of the collection's Seq
+//	tables    the FM-index tables in the format of gostr.ReadFMIndex
+//
+// For a suffix array index, the tables only hold the alphabet and the
+// suffix array.
+
+const (
+	indexMagic   = "GOSTRIDX"
+	indexVersion = 1
+)
+
+type indexFile struct {
+	c    *gostr.SeqCollection
+	tbls *gostr.FMIndexTables
+}
+
+// byteCounter counts the bytes written through it, so we can tell the
+// user how large the index is.
+type byteCounter struct {
+	w io.Writer
+	n int64
+}
+
+func (bc *byteCounter) Write(p []byte) (int, error) {
+	n, err := bc.w.Write(p)
+	bc.n += int64(n)
+
+	return n, err
+}
+
+func writeIndex(w io.Writer, idx *indexFile) error {
+	bw := bufio.NewWriter(w)
+	le := binary.LittleEndian
+
+	if _, err := bw.WriteString(indexMagic); err != nil {
+		return err
+	}
+
+	header := []interface{}{uint32(indexVersion), uint32(idx.c.Sep), uint64(len(idx.c.Names))}
+	for _, v := range header {
+		if err := binary.Write(bw, le, v); err != nil {
+			return err
+		}
+	}
+
+	for i, name := range idx.c.Names {
+		if err := writeString(bw, name); err != nil {
+			return err
+		}
+
+		if err := binary.Write(bw, le, uint64(idx.c.Starts[i])); err != nil {
+			return err
+		}
+	}
+
+	if err := writeString(bw, idx.c.Seq); err != nil {
+		return err
+	}
+
+	if _, err := idx.tbls.WriteTo(bw); err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+func writeString(w io.Writer, s string) error {
+	if err := binary.Write(w, binary.LittleEndian, uint64(len(s))); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, s)
+
+	return err
+}
+
+// saveIndex writes the index to a file and returns its size.
+func saveIndex(path string, idx *indexFile) (size int64, err error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	bc := &byteCounter{w: f}
+	err = writeIndex(bc, idx)
+
+	return bc.n, err
+}
+
+func errNotIndex(reason string) error {
+	return fmt.Errorf("not a gostr index file: %s", reason) //nolint:goerr113 // a message for the user
+}
+
+func readLength(r io.Reader) (int, error) {
+	var n uint64
+	if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+		return 0, err
+	}
+
+	if n > math.MaxInt32 {
+		return 0, errNotIndex("length out of range")
+	}
+
+	return int(n), nil
+}
+
+func readString(r io.Reader) (string, error) {
+	n, err := readLength(r)
+	if err != nil {
+		return "", err
+	}
+
+	// Don't trust the length blindly; read in chunks so a corrupt
+	// length gives us an error instead of a huge allocation.
+	var buf bytes.Buffer
+	if _, err := io.CopyN(&buf, r, int64(n)); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+func readIndex(r io.Reader) (idx *indexFile, err error) {
+	br := bufio.NewReader(r)
+	le := binary.LittleEndian
+
+	// Any EOF here means that the file is too short
+	defer func() {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			err = errNotIndex("the file is truncated")
+		}
+	}()
+
+	magic := make([]byte, len(indexMagic))
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return nil, err
+	}
+
+	if string(magic) != indexMagic {
+		return nil, errNotIndex("wrong magic bytes")
+	}
+
+	var version, sep uint32
+	if err := binary.Read(br, le, &version); err != nil {
+		return nil, err
+	}
+
+	if version != indexVersion {
+		return nil, errNotIndex(fmt.Sprintf("unsupported version %d", version))
+	}
+
+	if err := binary.Read(br, le, &sep); err != nil {
+		return nil, err
+	}
+
+	noRecords, err := readLength(br)
+	if err != nil {
+		return nil, err
+	}
+
+	c := gostr.SeqCollection{Sep: byte(sep)}
+
+	for i := 0; i < noRecords; i++ {
+		name, err := readString(br)
+		if err != nil {
+			return nil, err
+		}
+
+		start, err := readLength(br)
+		if err != nil {
+			return nil, err
+		}
+
+		c.Names = append(c.Names, name)
+		c.Starts = append(c.Starts, start)
+	}
+
+	if c.Seq, err = readString(br); err != nil {
+		return nil, err
+	}
+
+	tbls, err := gostr.ReadFMIndex(br)
+	if err != nil {
+		return nil, err
+	}
+
+	return &indexFile{c: &c, tbls: tbls}, nil
+}
+
+func loadIndex(path string) (*indexFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	return readIndex(f)
+}
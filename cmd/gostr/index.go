@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/mailund/gostr/gostr"
+	"github.com/mailund/gostr/reader"
+)
+
+func buildSAIndex(in io.Reader) (*indexFile, error) {
+	records, err := reader.ReadFasta(in)
+	if err != nil {
+		return nil, err
+	}
+
+	seqs := make([]gostr.SeqRecord, len(records))
+	for i, rec := range records {
+		seqs[i] = gostr.SeqRecord{Name: rec.Name, Seq: rec.Seq}
+	}
+
+	c, err := gostr.NewSeqCollection(seqs)
+	if err != nil {
+		return nil, err
+	}
+
+	alpha := gostr.NewAlphabet(c.Seq)
+
+	sa, err := gostr.SaisWithAlphabet(c.Seq, alpha)
+	if err != nil {
+		return nil, err
+	}
+
+	return &indexFile{c: c, tbls: &gostr.FMIndexTables{Alpha: alpha, Sa: sa}}, nil
+}
+
+func buildFMIndex(in io.Reader, opts []gostr.FMIndexOption) (*indexFile, error) {
+	c, tbls, err := reader.FMIndexFromFasta(in, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &indexFile{c: c, tbls: tbls}, nil
+}
+
+func runIndex(args []string, stdin io.Reader, _, stderr io.Writer) error {
+	fs := newFlagSet("index", "-o index [ref.fa|-]", stderr)
+	var (
+		kind         = fs.String("type", "fm", "the index to build: fm (FM-index) or sa (suffix array)")
+		out          = fs.String("o", "", "the file to write the index to")
+		saSampling   = fs.Int("sa-sampling", 1, "keep every k'th suffix array entry (fm only)")
+		otabSampling = fs.Int("otab-sampling", 0, "use a sampled o-table with this rate (fm only)")
+		wavelet      = fs.Bool("wavelet", false, "use a wavelet matrix for the rank tables (fm only)")
+	)
+
+	if err := parseFlags(fs, args, 0, 1); err != nil {
+		return err
+	}
+
+	if *out == "" {
+		fs.Usage()
+		return errUsage
+	}
+
+	in, err := openInput(fs.Arg(0), stdin)
+	if err != nil {
+		return err
+	}
+
+	defer in.Close()
+
+	var idx *indexFile
+
+	switch *kind {
+	case "fm":
+		opts := []gostr.FMIndexOption{gostr.WithSASampling(*saSampling)}
+		if *otabSampling > 0 {
+			opts = append(opts, gostr.WithOTabSampling(*otabSampling))
+		}
+
+		if *wavelet {
+			opts = append(opts, gostr.WithWaveletOTab())
+		}
+
+		idx, err = buildFMIndex(in, opts)
+	case "sa":
+		idx, err = buildSAIndex(in)
+	default:
+		return fmt.Errorf("unknown index type %q", *kind) //nolint:goerr113 // a message for the user
+	}
+
+	if err != nil {
+		return err
+	}
+
+	size, err := saveIndex(*out, idx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stderr, "wrote %s index of %d records (%d bytes) to %s\n", *kind, len(idx.c.Names), size, *out)
+
+	return nil
+}
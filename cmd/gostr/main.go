@@ -0,0 +1,76 @@
+// Command gostr builds and queries the string indices from the gostr
+// package.
+//
+// Usage:
+//
+//	gostr index [-type fm|sa] [-sa-sampling k] [-otab-sampling k] [-wavelet] -o index ref.fa
+//	gostr search [-k edits] [-rc] [-sam] index [patterns|-]
+//	gostr bwt [-sentinel $] [input|-]
+//	gostr unbwt [-sentinel $] [input|-]
+//	gostr sa [-alg sais|skew] [input|-]
+//
+// Where a file argument is "-" or missing, the command reads from stdin.
+// Search results are written as tab-separated lines with the pattern
+// name, the record name, the (zero-based) offset in the record and, for
+// approximative search, the cigar. With -rc, we also search for the
+// reverse complement of the patterns and add the strand, + or -, after
+// the offset; the reference must then be DNA. With -sam, the hits are written as SAM.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+type command struct {
+	run   func(args []string, stdin io.Reader, stdout, stderr io.Writer) error
+	usage string
+}
+
+var commands = map[string]command{ //nolint:gochecknoglobals // a constant table
+	"index":  {runIndex, "build an index from a FASTA file"},
+	"search": {runSearch, "search for patterns in an index"},
+	"bwt":    {runBwt, "compute the Burrows-Wheeler transform of a string"},
+	"unbwt":  {runUnbwt, "reverse the Burrows-Wheeler transform"},
+	"sa":     {runSa, "compute the suffix array of a string"},
+}
+
+var commandOrder = []string{"index", "search", "bwt", "unbwt", "sa"} //nolint:gochecknoglobals // a constant
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: gostr <command> [arguments]")
+	fmt.Fprintln(w, "\nThe commands are:")
+
+	for _, name := range commandOrder {
+		fmt.Fprintf(w, "\t%-8s%s\n", name, commands[name].usage)
+	}
+
+	fmt.Fprintln(w, "\nUse \"gostr <command> -h\" for the arguments of a command.")
+}
+
+// run dispatches to the command named by the first argument.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	if len(args) < 1 {
+		usage(stderr)
+		return errUsage
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		usage(stderr)
+		return fmt.Errorf("unknown command %q", args[0]) //nolint:goerr113 // a message for the user
+	}
+
+	return cmd.run(args[1:], stdin, stdout, stderr)
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr); err != nil {
+		if err != errUsage {
+			fmt.Fprintln(os.Stderr, "gostr:", err)
+		}
+
+		os.Exit(1)
+	}
+}
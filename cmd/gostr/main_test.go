@@ -0,0 +1,249 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+const testFasta = ">chr1 first\nmississippi\n>chr2\nssippii\n"
+
+func runCmd(t *testing.T, stdin string, args ...string) (string, error) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	err := run(args, strings.NewReader(stdin), &stdout, &stderr)
+
+	return stdout.String(), err
+}
+
+func buildTestIndex(t *testing.T, kind string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	ref := filepath.Join(dir, "ref.fa")
+
+	if err := os.WriteFile(ref, []byte(testFasta), 0o600); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	idx := filepath.Join(dir, "ref.idx")
+	if _, err := runCmd(t, "", "index", "-type", kind, "-o", idx, ref); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	return idx
+}
+
+func sortedLines(s string) []string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	sort.Strings(lines)
+
+	return lines
+}
+
+func TestSearchExact(t *testing.T) {
+	expected := "ssi\tchr1\t2\nssi\tchr1\t5\nssi\tchr2\t0"
+
+	for _, kind := range []string{"fm", "sa"} {
+		idx := buildTestIndex(t, kind)
+
+		out, err := runCmd(t, ">ssi\nssi\n>none\nxxx\n", "search", idx)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		if got := strings.Join(sortedLines(out), "\n"); got != expected {
+			t.Errorf("Got %q from the %s index, expected %q", got, kind, expected)
+		}
+	}
+}
+
+func TestSearchApprox(t *testing.T) {
+	idx := buildTestIndex(t, "fm")
+
+	out, err := runCmd(t, "ssi\n", "search", "-k", "1", idx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	for _, hit := range []string{"1\tchr1\t2\t3M", "1\tchr1\t5\t3M", "1\tchr2\t0\t3M"} {
+		if !strings.Contains(out, hit+"\n") {
+			t.Errorf("Expected the hit %q in %q", hit, out)
+		}
+	}
+
+	if _, err := runCmd(t, "ssi\n", "search", "-k", "1", buildTestIndex(t, "sa")); err == nil {
+		t.Error("Expected an error for approximative search in a suffix array")
+	}
+}
+
+func TestSearchSAM(t *testing.T) {
+	idx := buildTestIndex(t, "fm")
+
+	out, err := runCmd(t, "@r1\nsippi\n+\nIIIII\n@r2\nxx\n+\n##\n", "search", "-sam", idx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	expected := []string{
+		"@HD\tVN:1.6\tSO:unsorted",
+		"@SQ\tSN:chr1\tLN:11",
+		"@SQ\tSN:chr2\tLN:7",
+		"@PG\tID:gostr\tPN:gostr",
+		"r1\t0\tchr1\t7\t255\t5M\t*\t0\t0\tsippi\tIIIII\tNM:i:0\tMD:Z:5",
+		"r1\t256\tchr2\t2\t255\t5M\t*\t0\t0\tsippi\tIIIII\tNM:i:0\tMD:Z:5",
+		"r2\t4\t*\t0\t0\t*\t*\t0\t0\txx\t##",
+	}
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %d lines, got %q", len(expected), out)
+	}
+
+	for i := range expected {
+		if lines[i] != expected[i] {
+			t.Errorf("Expected line %q, got %q", expected[i], lines[i])
+		}
+	}
+}
+
+func TestBwtRoundTrip(t *testing.T) {
+	bwt, err := runCmd(t, "mississippi\n", "bwt")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if bwt != "ipssm$pissii\n" {
+		t.Errorf("Unexpected bwt %q", bwt)
+	}
+
+	x, err := runCmd(t, bwt, "unbwt")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if x != "mississippi\n" {
+		t.Errorf("Unexpected reversal %q", x)
+	}
+
+	// The letters are bytes, so we get non-ASCII text back unchanged
+	if bwt, err = runCmd(t, "h\xe9llo w\xc3\xb6rld\n", "bwt"); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if x, err = runCmd(t, bwt, "unbwt"); err != nil || x != "h\xe9llo w\xc3\xb6rld\n" {
+		t.Errorf("Unexpected reversal %q (%v)", x, err)
+	}
+
+	if _, err := runCmd(t, "a$b\n", "bwt"); err == nil {
+		t.Error("Expected an error when the input contains the sentinel")
+	}
+
+	if _, err := runCmd(t, "ab\n", "unbwt"); err == nil {
+		t.Error("Expected an error when the input has no sentinel")
+	}
+}
+
+func TestSuffixArray(t *testing.T) {
+	for _, alg := range []string{"sais", "skew"} {
+		out, err := runCmd(t, "mississippi\n", "sa", "-alg", alg)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		if got := strings.Fields(out); strings.Join(got, " ") != "11 10 7 4 1 0 9 8 6 3 5 2" {
+			t.Errorf("Unexpected suffix array from %s: %v", alg, got)
+		}
+	}
+}
+
+func TestUsageErrors(t *testing.T) {
+	calls := [][]string{
+		{},
+		{"foo"},
+		{"index", "ref.fa"},
+		{"index", "-type", "foo", "-o", "x", "-"},
+		{"search"},
+		{"sa", "-alg", "foo"},
+		{"bwt", "-sentinel", "ab"},
+		{"search", filepath.Join(t.TempDir(), "missing")},
+	}
+
+	for _, args := range calls {
+		if _, err := runCmd(t, "", args...); err == nil {
+			t.Errorf("Expected an error for %v", args)
+		}
+	}
+}
+
+func TestReadIndexErrors(t *testing.T) {
+	idx := buildTestIndex(t, "fm")
+
+	data, err := os.ReadFile(idx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	for _, bad := range [][]byte{data[:4], data[:30], []byte("NOTANIDXxxxxxxxx")} {
+		if _, err := readIndex(bytes.NewReader(bad)); err == nil || !strings.HasPrefix(err.Error(), "not a gostr index file") {
+			t.Errorf("Expected a bad index error, got %v", err)
+		}
+	}
+}
+
+func TestSearchReverseComplement(t *testing.T) {
+	dir := t.TempDir()
+	ref := filepath.Join(dir, "ref.fa")
+
+	if err := os.WriteFile(ref, []byte(">chr1\nAACCGT\n>chr2\nTTGCA\n"), 0o600); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	idx := filepath.Join(dir, "ref.idx")
+
+	for _, kind := range []string{"sa", "fm"} {
+		if _, err := runCmd(t, "", "index", "-type", kind, "-o", idx, ref); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		out, err := runCmd(t, "CAA\nTGCA\n", "search", "-rc", idx)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		// TGCA is its own reverse complement, so it is only reported once
+		expected := "1\tchr2\t0\t-\n2\tchr2\t1\t+"
+		if got := strings.Join(sortedLines(out), "\n"); got != expected {
+			t.Errorf("Got %q from the %s index, expected %q", got, kind, expected)
+		}
+	}
+
+	out, err := runCmd(t, "@r\nCAA\n+\nABC\n", "search", "-rc", "-sam", idx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if hit := "r\t16\tchr2\t1\t255\t3M\t*\t0\t0\tTTG\tCBA\tNM:i:0\tMD:Z:3\n"; !strings.Contains(out, hit) {
+		t.Errorf("Expected %q in %q", hit, out)
+	}
+
+	// We can only search the reverse strand of DNA
+	prot := filepath.Join(dir, "prot.fa")
+	if err := os.WriteFile(prot, []byte(">p\nMKLV\n"), 0o600); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	for _, kind := range []string{"sa", "fm"} {
+		if _, err := runCmd(t, "", "index", "-type", kind, "-o", idx, prot); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		if _, err := runCmd(t, "KL\n", "search", "-rc", idx); err == nil {
+			t.Errorf("Expected an error for the reverse strand of a protein %s index", kind)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mailund/gostr/gostr"
+	"github.com/mailund/gostr/reader"
+)
+
+// hitFunc is what the searches call for each hit. Exact searches
+// report a cigar with a match for each letter in the pattern, and
+// searches that don't look at the reverse strand only report hits on
+// the forward strand.
+type hitFunc = func(name string, offset int, strand gostr.Strand, cigar string)
+
+type searchFunc = func(p string, cb hitFunc)
+
+// saExactSearch searches in a suffix array index by binary search.
+func saExactSearch(c *gostr.SeqCollection, sa []int32, strands bool) searchFunc {
+	x := c.Seq
+	suffix := func(i int) string { return x[sa[i]:] }
+
+	search := func(p string, strand gostr.Strand, cb hitFunc) {
+		left := sort.Search(len(sa), func(i int) bool { return suffix(i) >= p })
+		right := left + sort.Search(len(sa)-left, func(i int) bool {
+			return !strings.HasPrefix(suffix(left+i), p)
+		})
+		cigar := fmt.Sprintf("%dM", len(p))
+
+		for i := left; i < right; i++ {
+			if name, offset, ok := c.Locate(int(sa[i]), len(p)); ok {
+				cb(name, offset, strand, cigar)
+			}
+		}
+	}
+
+	if !strands {
+		return func(p string, cb hitFunc) { search(p, gostr.Forward, cb) }
+	}
+
+	return func(p string, cb hitFunc) {
+		gostr.SearchStrands(p, func(p string, strand gostr.Strand) { search(p, strand, cb) })
+	}
+}
+
+func fmExactSearch(c *gostr.SeqCollection, tbls *gostr.FMIndexTables, strands bool) (searchFunc, error) {
+	if !strands {
+		search := c.FMIndexExactFromTables(tbls)
+
+		return func(p string, cb hitFunc) {
+			cigar := fmt.Sprintf("%dM", len(p))
+			search(p, func(name string, offset int) { cb(name, offset, gostr.Forward, cigar) })
+		}, nil
+	}
+
+	search, err := c.FMIndexExactStrandedFromTables(tbls)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(p string, cb hitFunc) {
+		cigar := fmt.Sprintf("%dM", len(p))
+		search(p, func(name string, offset int, strand gostr.Strand) { cb(name, offset, strand, cigar) })
+	}, nil
+}
+
+func fmApproxSearch(c *gostr.SeqCollection, tbls *gostr.FMIndexTables, edits int, strands bool) (searchFunc, error) {
+	if !strands {
+		search := c.FMIndexApproxFromTables(tbls)
+
+		return func(p string, cb hitFunc) {
+			search(p, edits, func(name string, offset int, cigar string) { cb(name, offset, gostr.Forward, cigar) })
+		}, nil
+	}
+
+	search, err := c.FMIndexApproxStrandedFromTables(tbls)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(p string, cb hitFunc) {
+		search(p, edits, cb)
+	}, nil
+}
+
+func newSearch(idx *indexFile, edits int, strands bool) (searchFunc, error) {
+	switch {
+	case idx.tbls.Ctab == nil && edits > 0:
+		return nil, fmt.Errorf("approximative search needs an FM-index") //nolint:goerr113 // a message for the user
+	case idx.tbls.Ctab == nil && strands && !idx.tbls.Alpha.IsDNA(idx.c.Sep):
+		return nil, fmt.Errorf("the reverse strand needs a DNA index") //nolint:goerr113 // a message for the user
+	case idx.tbls.Ctab == nil:
+		return saExactSearch(idx.c, idx.tbls.Sa, strands), nil
+	case edits > 0 && idx.tbls.Rotab == nil:
+		return nil, fmt.Errorf("the index was not built for approximative search") //nolint:goerr113 // a message for the user
+	case edits > 0:
+		return fmApproxSearch(idx.c, idx.tbls, edits, strands)
+	default:
+		return fmExactSearch(idx.c, idx.tbls, strands)
+	}
+}
+
+// hitWriter writes the hits for one pattern at a time.
+type hitWriter interface {
+	header(c *gostr.SeqCollection) error
+	hit(rec *reader.Record, name string, offset int, strand gostr.Strand, cigar string) error
+	done(rec *reader.Record) error // called after the last hit for rec
+}
+
+type tsvWriter struct {
+	w       io.Writer
+	approx  bool
+	strands bool
+}
+
+func (tw *tsvWriter) header(*gostr.SeqCollection) error {
+	return nil
+}
+
+func (tw *tsvWriter) hit(rec *reader.Record, name string, offset int, strand gostr.Strand, cigar string) error {
+	fields := []string{rec.Name, name, strconv.Itoa(offset)}
+
+	if tw.strands {
+		fields = append(fields, strand.String())
+	}
+
+	if tw.approx {
+		fields = append(fields, cigar)
+	}
+
+	_, err := fmt.Fprintln(tw.w, strings.Join(fields, "\t"))
+
+	return err
+}
+
+func (tw *tsvWriter) done(*reader.Record) error {
+	return nil
+}
+
+// samWriter writes hits as SAM records. It collects the hits for a
+// pattern, and the SAM writer picks the primary alignment among them
+// when we are done with the pattern.
+type samWriter struct {
+	w    io.Writer
+	sw   *gostr.SAMWriter
+	hits []gostr.SAMHit
+}
+
+func (sw *samWriter) header(c *gostr.SeqCollection) error {
+	sw.sw = gostr.NewSAMWriter(sw.w, c)
+	return sw.sw.WriteHeader()
+}
+
+func (sw *samWriter) hit(rec *reader.Record, name string, offset int, strand gostr.Strand, cigar string) error {
+	seq, qual, err := gostr.OrientRead(rec.Seq, rec.Qual, strand)
+	if err != nil {
+		return err
+	}
+
+	sw.hits = append(sw.hits, gostr.SAMHit{
+		Seq: seq, Qual: qual,
+		RName: name, Pos: offset, Cigar: cigar,
+		Reverse: strand == gostr.Reverse,
+	})
+
+	return nil
+}
+
+func (sw *samWriter) done(rec *reader.Record) error {
+	defer func() { sw.hits = sw.hits[:0] }()
+
+	return sw.sw.WriteRead(rec.Name, rec.Seq, rec.Qual, sw.hits)
+}
+
+func runSearch(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := newFlagSet("search", "index [patterns|-]", stderr)
+	var (
+		edits = fs.Int("k", 0, "the number of edits to allow")
+		sam   = fs.Bool("sam", false, "write the hits as SAM")
+		rc    = fs.Bool("rc", false, "also search for the reverse complement of the patterns")
+	)
+
+	if err := parseFlags(fs, args, 1, 2); err != nil {
+		return err
+	}
+
+	idx, err := loadIndex(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	search, err := newSearch(idx, *edits, *rc)
+	if err != nil {
+		return err
+	}
+
+	in, err := openInput(fs.Arg(1), stdin)
+	if err != nil {
+		return err
+	}
+
+	defer in.Close()
+
+	patterns, err := reader.NewRecordReader(in)
+	if err != nil {
+		return err
+	}
+
+	out := bufio.NewWriter(stdout)
+
+	var hw hitWriter = &tsvWriter{w: out, approx: *edits > 0, strands: *rc}
+	if *sam {
+		hw = &samWriter{w: out}
+	}
+
+	if err := hw.header(idx.c); err != nil {
+		return err
+	}
+
+	for {
+		rec, err := patterns.Read()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		search(rec.Seq, func(name string, offset int, strand gostr.Strand, cigar string) {
+			if err == nil {
+				err = hw.hit(rec, name, offset, strand, cigar)
+			}
+		})
+
+		if err != nil {
+			return err
+		}
+
+		if err := hw.done(rec); err != nil {
+			return err
+		}
+	}
+
+	return out.Flush()
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/mailund/gostr/gostr"
+)
+
+// sentinelFlag adds the flag for the letter we use to show the sentinel.
+func sentinelFlag(fs interface {
+	String(name, value, usage string) *string
+}) *string {
+	return fs.String("sentinel", "$", "the letter that represents the sentinel")
+}
+
+func checkSentinel(sentinel string) error {
+	if len(sentinel) != 1 || sentinel[0] == 0 {
+		return fmt.Errorf("the sentinel must be a single, non-zero byte, not %q", sentinel) //nolint:goerr113 // a message for the user
+	}
+
+	return nil
+}
+
+func runBwt(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := newFlagSet("bwt", "[input|-]", stderr)
+	sentinel := sentinelFlag(fs)
+
+	if err := parseFlags(fs, args, 0, 1); err != nil {
+		return err
+	}
+
+	if err := checkSentinel(*sentinel); err != nil {
+		return err
+	}
+
+	x, err := readText(fs.Arg(0), stdin)
+	if err != nil {
+		return err
+	}
+
+	if strings.Contains(x, *sentinel) || strings.IndexByte(x, 0) >= 0 {
+		return fmt.Errorf("the input contains the sentinel") //nolint:goerr113 // a message for the user
+	}
+
+	// We can compute the BWT directly on the input bytes, with a
+	// zero sentinel, so we don't need to map them to an alphabet.
+	xb := append([]byte(x), 0)
+	bwt := gostr.Bwt(xb, gostr.Sais(x))
+
+	for i, a := range bwt {
+		if a == 0 {
+			bwt[i] = (*sentinel)[0]
+		}
+	}
+
+	_, err = fmt.Fprintf(stdout, "%s\n", bwt)
+
+	return err
+}
+
+func runUnbwt(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := newFlagSet("unbwt", "[input|-]", stderr)
+	sentinel := sentinelFlag(fs)
+
+	if err := parseFlags(fs, args, 0, 1); err != nil {
+		return err
+	}
+
+	if err := checkSentinel(*sentinel); err != nil {
+		return err
+	}
+
+	text, err := readText(fs.Arg(0), stdin)
+	if err != nil {
+		return err
+	}
+
+	if strings.Count(text, *sentinel) != 1 || strings.IndexByte(text, 0) >= 0 {
+		return fmt.Errorf("the input must contain the sentinel exactly once") //nolint:goerr113 // a message for the user
+	}
+
+	// Map to a dense alphabet, with the sentinel at zero, since that
+	// is what ReverseBwt needs.
+	text = strings.Replace(text, *sentinel, "\x00", 1)
+	alpha := gostr.NewAlphabet(text)
+
+	bwt, err := alpha.MapToBytes(text)
+	if err != nil {
+		return err
+	}
+
+	x := alpha.RevmapBytesStripSentinel(gostr.ReverseBwt(bwt))
+
+	_, err = fmt.Fprintf(stdout, "%s\n", x)
+
+	return err
+}
+
+func runSa(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := newFlagSet("sa", "[input|-]", stderr)
+	alg := fs.String("alg", "sais", "the construction algorithm: sais or skew")
+
+	if err := parseFlags(fs, args, 0, 1); err != nil {
+		return err
+	}
+
+	var construct func(string) []int32
+
+	switch *alg {
+	case "sais":
+		construct = gostr.Sais
+	case "skew":
+		construct = gostr.Skew
+	default:
+		return fmt.Errorf("unknown algorithm %q", *alg) //nolint:goerr113 // a message for the user
+	}
+
+	x, err := readText(fs.Arg(0), stdin)
+	if err != nil {
+		return err
+	}
+
+	out := bufio.NewWriter(stdout)
+
+	for _, i := range construct(x) {
+		out.WriteString(strconv.Itoa(int(i)))
+		out.WriteByte('\n')
+	}
+
+	return out.Flush()
+}
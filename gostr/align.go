@@ -0,0 +1,328 @@
+package gostr
+
+import "math"
+
+// Pairwise alignment with affine gap costs (Gotoh's algorithm). We
+// align a query, p, against a reference, x, and, as with the cigars
+// from the searches, an M aligns a letter from each, an I is a letter
+// from p against a gap, and a D is a letter from x against a gap.
+//
+// We fill in three tables, one for each operation an alignment can end
+// with: M[i][j], I[i][j] and D[i][j] are the best scores of alignments
+// of prefixes x[:i] and p[:j] that end with an M, an I or a D. The
+// three kinds of alignment differ only in where they can start and
+// end.
+
+// SubstMatrix holds the score for aligning any two bytes.
+type SubstMatrix [256][256]int32
+
+// NewSubstMatrix returns a substitution matrix that scores all pairs
+// of equal bytes with match and all other pairs with mismatch. You can
+// change individual scores afterwards with Set.
+func NewSubstMatrix(match, mismatch int) *SubstMatrix {
+	var m SubstMatrix
+
+	for a := range m {
+		for b := range m[a] {
+			m[a][b] = int32(mismatch)
+		}
+
+		m[a][a] = int32(match)
+	}
+
+	return &m
+}
+
+// Set sets the score for aligning a against b.
+func (m *SubstMatrix) Set(a, b byte, score int) {
+	m[a][b] = int32(score)
+}
+
+// Score returns the score for aligning a against b.
+func (m *SubstMatrix) Score(a, b byte) int {
+	return int(m[a][b])
+}
+
+// AlignScoring is a scoring scheme for alignments. A gap of length k
+// scores -(GapOpen + k*GapExtend), so the costs should be non-negative.
+type AlignScoring struct {
+	Subst              *SubstMatrix
+	GapOpen, GapExtend int
+}
+
+// EditScoring is a scoring where the score of an alignment is minus
+// the number of edits in it, the way CountEdits counts them.
+func EditScoring() *AlignScoring {
+	return &AlignScoring{Subst: NewSubstMatrix(0, -1), GapOpen: 0, GapExtend: 1}
+}
+
+// Alignment is the result of aligning p against x. The alignment
+// starts at position Pos in x and QPos in p, and QPos is only
+// different from zero for local alignments. You can get the aligned
+// strings with ExtractAlignment(x, p[QPos:], Pos, Cigar).
+type Alignment struct {
+	Score     int
+	Pos, QPos int
+	Cigar     string
+}
+
+// Aligner aligns strings with a scoring scheme. It reuses its tables
+// between alignments, so it is cheaper to align many strings with one
+// aligner than to use a new one each time, but it also means that you
+// cannot use the same aligner from more than one goroutine at a time.
+//
+// The aligner never copies the strings it aligns, so to align against
+// a window of a longer text, just slice it: Go doesn't copy when you
+// slice a string. The positions in the alignment are then relative to
+// the window.
+type Aligner struct {
+	scoring *AlignScoring
+	m       int // the length of p, i.e., the row length minus one
+	mtab    []int
+	itab    []int
+	dtab    []int
+}
+
+// NewAligner returns an aligner that uses scoring.
+func NewAligner(scoring *AlignScoring) *Aligner {
+	return &Aligner{scoring: scoring}
+}
+
+// alignMode is the kind of alignment we compute.
+type alignMode int
+
+const (
+	globalAlign     alignMode = iota // all of x against all of p
+	semiGlobalAlign                  // all of p against a substring of x
+	localAlign                       // a substring of p against a substring of x
+)
+
+// Well below any score we can get, but far enough from the smallest
+// int that we can subtract gap costs without wrapping around.
+const negInf = math.MinInt / 4
+
+// canStart tells us if an alignment can start after x[:i] and p[:j].
+func (mode alignMode) canStart(i, j int) bool {
+	switch mode {
+	case globalAlign:
+		return i == 0 && j == 0
+	case semiGlobalAlign:
+		return j == 0
+	default:
+		return true
+	}
+}
+
+func (al *Aligner) idx(i, j int) int {
+	return i*(al.m+1) + j
+}
+
+// best returns the best score of an alignment of x[:i] and p[:j],
+// including the empty alignment if one can start there.
+func (al *Aligner) best(mode alignMode, i, j int) int {
+	k := al.idx(i, j)
+
+	score := al.mtab[k]
+	if al.itab[k] > score {
+		score = al.itab[k]
+	}
+
+	if al.dtab[k] > score {
+		score = al.dtab[k]
+	}
+
+	if mode.canStart(i, j) && score < 0 {
+		score = 0
+	}
+
+	return score
+}
+
+func (al *Aligner) resize(n, m int) {
+	al.m = m
+
+	size := (n + 1) * (m + 1)
+	if cap(al.mtab) < size {
+		al.mtab = make([]int, size)
+		al.itab = make([]int, size)
+		al.dtab = make([]int, size)
+	}
+
+	al.mtab, al.itab, al.dtab = al.mtab[:size], al.itab[:size], al.dtab[:size]
+}
+
+func (al *Aligner) fill(mode alignMode, x, p string) {
+	al.resize(len(x), len(p))
+
+	open, ext := al.scoring.GapOpen, al.scoring.GapExtend
+
+	for i := 0; i <= len(x); i++ {
+		for j := 0; j <= len(p); j++ {
+			k := al.idx(i, j)
+			al.mtab[k], al.itab[k], al.dtab[k] = negInf, negInf, negInf
+
+			if i > 0 && j > 0 {
+				al.mtab[k] = al.best(mode, i-1, j-1) + al.scoring.Subst.Score(x[i-1], p[j-1])
+			}
+
+			if j > 0 {
+				al.itab[k] = al.best(mode, i, j-1) - open - ext
+				if extended := al.itab[al.idx(i, j-1)] - ext; extended > al.itab[k] {
+					al.itab[k] = extended
+				}
+			}
+
+			if i > 0 {
+				al.dtab[k] = al.best(mode, i-1, j) - open - ext
+				if extended := al.dtab[al.idx(i-1, j)] - ext; extended > al.dtab[k] {
+					al.dtab[k] = extended
+				}
+			}
+		}
+	}
+}
+
+// traceback collects the operations of the best alignment that ends
+// with op after x[:i] and p[:j] and returns where it starts.
+func (al *Aligner) traceback(mode alignMode, i, j int, op ApproxEdit) (starti, startj int, cigar string) {
+	ops := EditOps{}
+	ext := al.scoring.GapExtend
+
+	// Where we go after an operation: we continue with the operation
+	// that gave the best score before it, or stop if the alignment
+	// starts there.
+	prev := func(i, j int) (ApproxEdit, bool) {
+		k := al.idx(i, j)
+
+		switch score := al.best(mode, i, j); score {
+		case al.mtab[k]:
+			return Match, true
+		case al.dtab[k]:
+			return Delete, true
+		case al.itab[k]:
+			return Insert, true
+		default:
+			return Match, false // the empty alignment starting here
+		}
+	}
+
+	for ok := true; ok; {
+		k := al.idx(i, j)
+		ops = append(ops, op)
+
+		switch op {
+		case Match:
+			i, j = i-1, j-1
+			op, ok = prev(i, j)
+
+		case Insert:
+			j--
+			if al.itab[k] != al.itab[al.idx(i, j)]-ext {
+				op, ok = prev(i, j)
+			}
+
+		case Delete:
+			i--
+			if al.dtab[k] != al.dtab[al.idx(i, j)]-ext {
+				op, ok = prev(i, j)
+			}
+		}
+	}
+
+	return i, j, OpsToCigar(revOps(&ops))
+}
+
+// align computes the best alignment that ends after x[:i] and p[:j].
+func (al *Aligner) align(mode alignMode, i, j int) Alignment {
+	k := al.idx(i, j)
+	score := al.best(mode, i, j)
+
+	var op ApproxEdit
+
+	switch score {
+	case al.mtab[k]:
+		op = Match
+	case al.dtab[k]:
+		op = Delete
+	case al.itab[k]:
+		op = Insert
+	default:
+		// The empty alignment is the best we can do
+		return Alignment{Score: 0, Pos: i, QPos: j, Cigar: ""}
+	}
+
+	starti, startj, cigar := al.traceback(mode, i, j, op)
+
+	return Alignment{Score: score, Pos: starti, QPos: startj, Cigar: cigar}
+}
+
+// Global returns the best alignment of all of p against all of x
+// (Needleman-Wunsch).
+func (al *Aligner) Global(x, p string) Alignment {
+	al.fill(globalAlign, x, p)
+	return al.align(globalAlign, len(x), len(p))
+}
+
+// SemiGlobal returns the best alignment of all of p against a
+// substring of x, also known as a fitting alignment. This is the
+// alignment we want when we verify a read against a window of the
+// text around a hit.
+func (al *Aligner) SemiGlobal(x, p string) Alignment {
+	al.fill(semiGlobalAlign, x, p)
+
+	// The alignment can end anywhere in x, but at the end of p. If
+	// several ends have the best score, we take the first.
+	end := 0
+	for i := 1; i <= len(x); i++ {
+		if al.best(semiGlobalAlign, i, len(p)) > al.best(semiGlobalAlign, end, len(p)) {
+			end = i
+		}
+	}
+
+	return al.align(semiGlobalAlign, end, len(p))
+}
+
+// Local returns the best alignment of a substring of p against a
+// substring of x (Smith-Waterman). If nothing aligns with a positive
+// score, it returns an empty alignment with score zero.
+func (al *Aligner) Local(x, p string) Alignment {
+	al.fill(localAlign, x, p)
+
+	// The alignment can end anywhere, but a local alignment never
+	// gets better by ending with a gap, so we only look at the ends
+	// of matches. If several ends have the best score, we take the
+	// first.
+	endi, endj, score := 0, 0, 0
+
+	for i := 1; i <= len(x); i++ {
+		for j := 1; j <= len(p); j++ {
+			if s := al.mtab[al.idx(i, j)]; s > score {
+				endi, endj, score = i, j, s
+			}
+		}
+	}
+
+	if score == 0 {
+		return Alignment{Score: 0, Pos: 0, QPos: 0, Cigar: ""}
+	}
+
+	starti, startj, cigar := al.traceback(localAlign, endi, endj, Match)
+
+	return Alignment{Score: score, Pos: starti, QPos: startj, Cigar: cigar}
+}
+
+// GlobalAlign returns the best global alignment of p against x.
+func GlobalAlign(x, p string, scoring *AlignScoring) Alignment {
+	return NewAligner(scoring).Global(x, p)
+}
+
+// SemiGlobalAlign returns the best alignment of all of p against a
+// substring of x.
+func SemiGlobalAlign(x, p string, scoring *AlignScoring) Alignment {
+	return NewAligner(scoring).SemiGlobal(x, p)
+}
+
+// LocalAlign returns the best local alignment of p against x.
+func LocalAlign(x, p string, scoring *AlignScoring) Alignment {
+	return NewAligner(scoring).Local(x, p)
+}
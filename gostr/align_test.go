@@ -0,0 +1,242 @@
+package gostr_test
+
+import (
+	"testing"
+
+	"github.com/mailund/gostr/gostr"
+	"github.com/mailund/gostr/testutils"
+)
+
+// scoreAlignment scores the alignment of p[qpos:] against x at pos
+// from scratch, following the cigar.
+func scoreAlignment(t *testing.T, x, p string, aln gostr.Alignment, scoring *gostr.AlignScoring) int {
+	t.Helper()
+
+	ops, err := gostr.CigarToOps(aln.Cigar)
+	if err != nil {
+		t.Fatalf("Invalid cigar %q: %v", aln.Cigar, err)
+	}
+
+	score, i, j := 0, aln.Pos, aln.QPos
+
+	for k, op := range ops {
+		switch op {
+		case gostr.Match:
+			score += scoring.Subst.Score(x[i], p[j])
+			i, j = i+1, j+1
+
+		case gostr.Insert:
+			j++
+
+		case gostr.Delete:
+			i++
+		}
+
+		if op != gostr.Match {
+			score -= scoring.GapExtend
+			if k == 0 || ops[k-1] != op {
+				score -= scoring.GapOpen
+			}
+		}
+	}
+
+	if i > len(x) || j > len(p) {
+		t.Fatalf("Alignment %v runs past the end of %q or %q", aln, x, p)
+	}
+
+	return score
+}
+
+// bruteGlobal finds the best global alignment score by trying all the
+// alignments. Only use it on short strings.
+func bruteGlobal(x, p string, scoring *gostr.AlignScoring) int {
+	var rec func(i, j int, last gostr.ApproxEdit, first bool) int
+
+	gap := func(op, last gostr.ApproxEdit, first bool) int {
+		if first || op != last {
+			return scoring.GapOpen + scoring.GapExtend
+		}
+
+		return scoring.GapExtend
+	}
+
+	rec = func(i, j int, last gostr.ApproxEdit, first bool) int {
+		if i == len(x) && j == len(p) {
+			return 0
+		}
+
+		best := -1 << 30
+
+		if i < len(x) && j < len(p) {
+			if s := scoring.Subst.Score(x[i], p[j]) + rec(i+1, j+1, gostr.Match, false); s > best {
+				best = s
+			}
+		}
+
+		if j < len(p) {
+			if s := rec(i, j+1, gostr.Insert, false) - gap(gostr.Insert, last, first); s > best {
+				best = s
+			}
+		}
+
+		if i < len(x) {
+			if s := rec(i+1, j, gostr.Delete, false) - gap(gostr.Delete, last, first); s > best {
+				best = s
+			}
+		}
+
+		return best
+	}
+
+	return rec(0, 0, gostr.Match, true)
+}
+
+func bruteSemiGlobal(x, p string, scoring *gostr.AlignScoring) int {
+	best := -1 << 30
+
+	for i := 0; i <= len(x); i++ {
+		for j := i; j <= len(x); j++ {
+			if s := bruteGlobal(x[i:j], p, scoring); s > best {
+				best = s
+			}
+		}
+	}
+
+	return best
+}
+
+func bruteLocal(x, p string, scoring *gostr.AlignScoring) int {
+	best := 0
+
+	for k := 0; k <= len(p); k++ {
+		for l := k; l <= len(p); l++ {
+			if s := bruteSemiGlobal(x, p[k:l], scoring); s > best {
+				best = s
+			}
+		}
+	}
+
+	return best
+}
+
+func TestAlignExamples(t *testing.T) {
+	edits := gostr.EditScoring()
+
+	if aln := gostr.GlobalAlign("sitting", "kitten", edits); aln.Score != -3 || aln.Pos != 0 {
+		t.Errorf("Expected edit distance 3 between kitten and sitting, got %v", aln)
+	}
+
+	if aln := gostr.SemiGlobalAlign("ttacgttt", "acgt", edits); aln.Score != 0 || aln.Pos != 2 || aln.Cigar != "4M" {
+		t.Errorf("Expected an exact match at 2, got %v", aln)
+	}
+
+	if aln := gostr.SemiGlobalAlign("ttacttt", "acgt", edits); aln.Score != -1 || aln.Pos != 2 {
+		t.Errorf("Expected a match with one edit at 2, got %v", aln)
+	}
+
+	scoring := &gostr.AlignScoring{Subst: gostr.NewSubstMatrix(2, -3), GapOpen: 5, GapExtend: 1}
+	if aln := gostr.LocalAlign("ggggacgtgggg", "tttacgttt", scoring); aln.Score != 8 || aln.Pos != 4 || aln.QPos != 3 || aln.Cigar != "4M" {
+		t.Errorf("Expected the local alignment of acgt, got %v", aln)
+	}
+
+	if aln := gostr.LocalAlign("aaaa", "cccc", scoring); aln.Score != 0 || aln.Cigar != "" {
+		t.Errorf("Expected an empty local alignment, got %v", aln)
+	}
+
+	// With affine gaps, one long gap is better than two short ones
+	if aln := gostr.GlobalAlign("aaccgg", "aagg", scoring); aln.Cigar != "2M2D2M" {
+		t.Errorf("Expected one gap, got %v", aln)
+	}
+
+	if aln := gostr.GlobalAlign("", "", scoring); aln.Score != 0 || aln.Cigar != "" {
+		t.Errorf("Expected an empty alignment, got %v", aln)
+	}
+
+	if aln := gostr.GlobalAlign("", "ac", scoring); aln.Score != -7 || aln.Cigar != "2I" {
+		t.Errorf("Expected a gap, got %v", aln)
+	}
+}
+
+func TestAlignRandom(t *testing.T) {
+	rng := testutils.NewRandomSeed(t)
+
+	scorings := []*gostr.AlignScoring{
+		gostr.EditScoring(),
+		{Subst: gostr.NewSubstMatrix(1, -1), GapOpen: 0, GapExtend: 2},
+		{Subst: gostr.NewSubstMatrix(2, -3), GapOpen: 3, GapExtend: 1},
+	}
+
+	// A matrix where transitions are less bad than transversions
+	dna := gostr.NewSubstMatrix(2, -3)
+	for _, pair := range []string{"ag", "ga", "ct", "tc"} {
+		dna.Set(pair[0], pair[1], -1)
+	}
+
+	scorings = append(scorings, &gostr.AlignScoring{Subst: dna, GapOpen: 2, GapExtend: 2})
+
+	for _, scoring := range scorings {
+		aligner := gostr.NewAligner(scoring) // reused across the alignments
+
+		for k := 0; k < 30; k++ {
+			x := testutils.RandomStringRange(0, 7, "acgt", rng)
+			p := testutils.RandomStringRange(0, 5, "acgt", rng)
+
+			global := aligner.Global(x, p)
+			if expected := bruteGlobal(x, p, scoring); global.Score != expected {
+				t.Errorf("Global score %v for %q and %q, expected %d", global, x, p, expected)
+			}
+
+			if s := scoreAlignment(t, x, p, global, scoring); s != global.Score {
+				t.Errorf("Global alignment %v of %q and %q scores %d", global, x, p, s)
+			}
+
+			semi := aligner.SemiGlobal(x, p)
+			if expected := bruteSemiGlobal(x, p, scoring); semi.Score != expected {
+				t.Errorf("Semi-global score %v for %q and %q, expected %d", semi, x, p, expected)
+			}
+
+			if semi.QPos != 0 || scoreAlignment(t, x, p, semi, scoring) != semi.Score {
+				t.Errorf("Semi-global alignment %v of %q and %q is inconsistent", semi, x, p)
+			}
+
+			if _, subp, err := gostr.ExtractAlignment(x, p, semi.Pos, semi.Cigar); err != nil || len(subp) < len(p) {
+				t.Errorf("Semi-global alignment %v doesn't cover %q", semi, p)
+			}
+
+			local := aligner.Local(x, p)
+			if expected := bruteLocal(x, p, scoring); local.Score != expected {
+				t.Errorf("Local score %v for %q and %q, expected %d", local, x, p, expected)
+			}
+
+			if scoreAlignment(t, x, p, local, scoring) != local.Score {
+				t.Errorf("Local alignment %v of %q and %q is inconsistent", local, x, p)
+			}
+		}
+	}
+}
+
+// With the edit scoring, the semi-global alignment of a read against
+// the window of the text around an FM-index hit has at most the edits
+// CountEdits finds for the hit, since the hit is one of the alignments
+// in the window.
+func TestAlignRescoreHits(t *testing.T) {
+	rng := testutils.NewRandomSeed(t)
+	aligner := gostr.NewAligner(gostr.EditScoring())
+
+	for k := 0; k < 20; k++ {
+		x := testutils.RandomStringRange(10, 50, "acg", rng)
+		p := testutils.RandomStringRange(1, 6, "acg", rng)
+		search := gostr.FMIndexApproxPreprocess(x)
+
+		for _, hit := range approxHits(t, x, p, 2, search) {
+			end := hit.Pos + len(p) + 2
+			if end > len(x) {
+				end = len(x)
+			}
+
+			if aln := aligner.SemiGlobal(x[hit.Pos:end], p); -aln.Score > hit.Edits {
+				t.Errorf("Hit %v of %q in %q rescores to %v", hit, p, x, aln)
+			}
+		}
+	}
+}
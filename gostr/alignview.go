@@ -0,0 +1,225 @@
+package gostr
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// The characters in the match line of an alignment view.
+const (
+	viewMatch    = '|'
+	viewMismatch = '.'
+	viewGap      = ' '
+)
+
+// AlignmentView is an alignment of a read against a reference laid out
+// for reading: the gapped reference and read, with a match line between
+// them that has '|' for matches, '.' for mismatches, and a space for
+// gaps. It also has the SAM NM (edit distance) and MD (the reference
+// letters at mismatches and deletions) tags for the alignment.
+type AlignmentView struct {
+	Ref, Match, Read string
+	Edits            int
+	MD               string
+
+	// The position in the reference and in the read of the letter in
+	// each column, or -1 for gaps.
+	refPos, readPos []int
+}
+
+// NewAlignmentView builds the view of the alignment of p against x at
+// pos that cigar describes.
+func NewAlignmentView(x, p string, pos int, cigar string) (*AlignmentView, error) {
+	c, err := ParseCigar(cigar)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.View(x, p, pos), nil
+}
+
+// View builds the view of the alignment of p against x at pos.
+func (c Cigar) View(x, p string, pos int) *AlignmentView {
+	var (
+		ref, match, read strings.Builder
+		md               strings.Builder
+
+		view  = AlignmentView{}
+		i, j  = pos, 0
+		run   int  // the matches since the last MD entry
+		inDel bool // if the last operation was a deletion
+	)
+
+	column := func(a, b, m byte, ai, bj int) {
+		ref.WriteByte(a)
+		match.WriteByte(m)
+		read.WriteByte(b)
+
+		view.refPos = append(view.refPos, ai)
+		view.readPos = append(view.readPos, bj)
+	}
+
+	for _, r := range c {
+		for k := 0; k < r.Len; k++ {
+			switch r.Op {
+			case Match, SeqMatch, Mismatch:
+				if x[i] == p[j] {
+					column(x[i], p[j], viewMatch, i, j)
+
+					run++
+				} else {
+					column(x[i], p[j], viewMismatch, i, j)
+
+					view.Edits++
+					md.WriteString(strconv.Itoa(run))
+					md.WriteByte(x[i])
+
+					run = 0
+				}
+
+				i, j = i+1, j+1
+
+			case Insert:
+				column('-', p[j], viewGap, -1, j)
+
+				view.Edits++
+				j++
+
+			case Delete:
+				column(x[i], '-', viewGap, i, -1)
+
+				if !inDel {
+					md.WriteString(strconv.Itoa(run))
+					md.WriteByte('^')
+
+					run = 0
+				}
+
+				view.Edits++
+				md.WriteByte(x[i])
+				i++
+
+			case Pad:
+				column('-', '-', viewGap, -1, -1)
+
+			case SoftClip:
+				j++
+
+			case Skip:
+				i++
+
+			case HardClip:
+				// not in either string
+			}
+
+			inDel = r.Op == Delete
+		}
+	}
+
+	md.WriteString(strconv.Itoa(run))
+
+	view.Ref, view.Match, view.Read = ref.String(), match.String(), read.String()
+	view.MD = md.String()
+
+	return &view
+}
+
+// lineRange returns the first and last position, one-indexed, of the
+// letters in columns [from,to) of an alignment row, or prev, the last
+// position we have printed, if they are all gaps.
+func lineRange(positions []int, from, to, prev int) (first, last int) {
+	first, last = -1, prev
+
+	for _, p := range positions[from:to] {
+		if p >= 0 {
+			if first < 0 {
+				first = p + 1
+			}
+
+			last = p + 1
+		}
+	}
+
+	if first < 0 {
+		first = prev
+	}
+
+	return first, last
+}
+
+// firstPos returns the first position, zero-indexed, in an alignment
+// row, or zero if it only has gaps.
+func firstPos(positions []int) int {
+	for _, p := range positions {
+		if p >= 0 {
+			return p
+		}
+	}
+
+	return 0
+}
+
+// Print writes the alignment to w the way BLAST does, with the
+// reference above the read and the match line between them, in blocks
+// of width columns, each line starting and ending with the positions,
+// counting from one, of the first and last letters on it. If width
+// isn't positive, it writes the whole alignment in one block.
+func (view *AlignmentView) Print(w io.Writer, width int) error {
+	n := len(view.Ref)
+	if width <= 0 {
+		width = n
+	}
+
+	// The widest coordinate, so the rows line up
+	widest := 0
+
+	for _, positions := range [][]int{view.refPos, view.readPos} {
+		for _, p := range positions {
+			if p+1 > widest {
+				widest = p + 1
+			}
+		}
+	}
+
+	digits := len(strconv.Itoa(widest))
+	prevRef, prevRead := firstPos(view.refPos), firstPos(view.readPos)
+
+	for from := 0; from < n; from += width {
+		to := from + width
+		if to > n {
+			to = n
+		}
+
+		if from > 0 {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+
+		refFirst, refLast := lineRange(view.refPos, from, to, prevRef)
+		readFirst, readLast := lineRange(view.readPos, from, to, prevRead)
+		prevRef, prevRead = refLast, readLast
+
+		_, err := fmt.Fprintf(w, "Ref  %*d  %s  %d\n     %*s  %s\nRead %*d  %s  %d\n",
+			digits, refFirst, view.Ref[from:to], refLast,
+			digits, "", view.Match[from:to],
+			digits, readFirst, view.Read[from:to], readLast)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// String returns the alignment the way Print writes it, in blocks of
+// sixty columns.
+func (view *AlignmentView) String() string {
+	var res strings.Builder
+
+	_ = view.Print(&res, 60) //nolint:gomnd // writing to a builder doesn't fail, and 60 is what BLAST uses
+
+	return res.String()
+}
@@ -0,0 +1,137 @@
+package gostr_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mailund/gostr/gostr"
+	"github.com/mailund/gostr/testutils"
+)
+
+func TestAlignmentView(t *testing.T) {
+	tests := []struct {
+		name             string
+		x, p             string
+		pos              int
+		cigar            string
+		ref, match, read string
+		edits            int
+		md               string
+	}{
+		{
+			"Exact", "acgtacgt", "gtac", 2, "4M",
+			"gtac", "||||", "gtac", 0, "4",
+		},
+		{
+			"Mismatches", "acgtacgt", "gcac", 2, "4M",
+			"gtac", "|.||", "gcac", 1, "1t2",
+		},
+		{
+			"Deletion", "acgtacgt", "gtc", 2, "2M1D1M",
+			"gtac", "|| |", "gt-c", 1, "2^a1",
+		},
+		{
+			"Deletion then mismatch", "acgtacgt", "gtgt", 2, "2M2D2M",
+			"gtacgt", "||  ||", "gt--gt", 2, "2^ac2",
+		},
+		{
+			"Mismatch after deletion", "acgtacgt", "gtt", 2, "2M1D1M",
+			"gtac", "|| .", "gt-t", 2, "2^a0c0",
+		},
+		{
+			"Insertion", "acgtacgt", "gtaac", 2, "2M1I2M",
+			"gt-ac", "|| ||", "gtaac", 1, "4",
+		},
+		{
+			"Clipped and skipped", "acgtacgt", "ttgtgt", 2, "1H2S2M2N2M",
+			"gtgt", "||||", "gtgt", 0, "4",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			view, err := gostr.NewAlignmentView(tt.x, tt.p, tt.pos, tt.cigar)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if view.Ref != tt.ref || view.Match != tt.match || view.Read != tt.read {
+				t.Errorf("Unexpected alignment\n%s\n%s\n%s", view.Ref, view.Match, view.Read)
+			}
+
+			if view.Edits != tt.edits || view.MD != tt.md {
+				t.Errorf("Got NM %d and MD %s, expected %d and %s", view.Edits, view.MD, tt.edits, tt.md)
+			}
+		})
+	}
+
+	if _, err := gostr.NewAlignmentView("acgt", "acgt", 0, "4Q"); !errors.Is(err, gostr.NewInvalidCigar("4Q", 1)) {
+		t.Errorf("Expected an invalid cigar error, got %v", err)
+	}
+}
+
+func TestAlignmentViewMatchesExtract(t *testing.T) {
+	rng := testutils.NewRandomSeed(t)
+
+	for i := 0; i < 50; i++ {
+		x := testutils.RandomStringRange(20, 40, "acg", rng)
+		p := testutils.RandomStringRange(3, 8, "acg", rng)
+		search := gostr.FMIndexApproxPreprocess(x)
+
+		search(p, 2, func(pos int, cigar string) {
+			view, _ := gostr.NewAlignmentView(x, p, pos, cigar)
+			subx, subp, _ := gostr.ExtractAlignment(x, p, pos, cigar)
+			edits, _ := gostr.CountEdits(x, p, pos, cigar)
+
+			if view.Ref != subx || view.Read != subp || view.Edits != edits {
+				t.Errorf("View of %s at %d doesn't match the extracted alignment", cigar, pos)
+			}
+		})
+	}
+}
+
+func TestAlignmentViewPrint(t *testing.T) {
+	x := "ttttttttacgtacgtacgt"
+	p := "acgtcgtaacg"
+
+	view, err := gostr.NewAlignmentView(x, p, 8, "4M1D3M1I3M")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := view.Print(&buf, 5); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := strings.Join([]string{
+		"Ref   9  acgta  13",
+		"         |||| ",
+		"Read  1  acgt-  4",
+		"",
+		"Ref  14  cgt-a  17",
+		"         ||| |",
+		"Read  5  cgtaa  9",
+		"",
+		"Ref  18  cg  19",
+		"         ||",
+		"Read 10  cg  11",
+		"",
+	}, "\n")
+
+	if buf.String() != expected {
+		t.Errorf("Got\n%s\nexpected\n%s", buf.String(), expected)
+	}
+
+	if view.String() != strings.Join([]string{
+		"Ref   9  acgtacgt-acg  19",
+		"         |||| ||| |||",
+		"Read  1  acgt-cgtaacg  11",
+		"",
+	}, "\n") {
+		t.Errorf("Unexpected string:\n%s", view.String())
+	}
+}
@@ -31,21 +31,25 @@ func NewAlphabet(ref string) *Alphabet {
 	}
 
 	alpha._map[0] = 1 // sentinel is always here
-	for i := range ref {
+	// Index the bytes directly; ranging over the string would
+	// skip bytes that are part of multi-byte UTF-8 runes.
+	for i := 0; i < len(ref); i++ {
 		alpha._map[ref[i]] = 1
 	}
 
-	var alphaSize byte // tracks alphabet size...
+	// tracks alphabet size... it has to be an int since
+	// we can have all 256 bytes, including the sentinel.
+	var alphaSize int
 
 	for a, tag := range alpha._map {
 		if tag == 1 {
-			alpha._map[a] = alphaSize
+			alpha._map[a] = byte(alphaSize)
 			alpha._revmap[alphaSize] = byte(a)
 			alphaSize++
 		}
 	}
 
-	alpha.size = int(alphaSize)
+	alpha.size = alphaSize
 
 	return &alpha
 }
@@ -124,17 +128,19 @@ func (alpha *Alphabet) revmapBytes(x []byte, stripSentinel bool) string {
 		strip++
 	}
 
-	out := make([]rune, len(x)-strip)
+	// The letters are bytes, and we must not encode those above 127 as
+	// runes, but the sentinel symbol is a rune.
+	var out bytes.Buffer
 
-	for i := 0; i < len(out); i++ {
-		if x[i] == Sentinel {
-			out[i] = SentinelSymbol
+	for _, a := range x[:len(x)-strip] {
+		if a == Sentinel {
+			out.WriteRune(SentinelSymbol)
 		} else {
-			out[i] = rune(alpha._revmap[x[i]])
+			out.WriteByte(alpha._revmap[a])
 		}
 	}
 
-	return string(out)
+	return out.String()
 }
 
 // RevmapBytes maps a byte slice back into a string according to the alphabet
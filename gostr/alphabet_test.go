@@ -177,3 +177,24 @@ func TestAlphabetEncoding(t *testing.T) {
 		t.Fatalf("These two alphabets should be equal now")
 	}
 }
+
+func TestAlphabetAllBytes(t *testing.T) {
+	allBytes := make([]byte, 255)
+	for i := range allBytes {
+		allBytes[i] = byte(i + 1)
+	}
+
+	alpha := gostr.NewAlphabet(string(allBytes))
+	if alpha.Size() != 256 {
+		t.Fatalf("Expected size 256, got %d", alpha.Size())
+	}
+
+	mapped, err := alpha.MapToBytes(string(allBytes))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if !reflect.DeepEqual(mapped, allBytes) {
+		t.Errorf("All bytes should map to themselves")
+	}
+}
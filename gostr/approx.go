@@ -1,11 +1,6 @@
 package gostr
 
-import (
-	"fmt"
-	"regexp"
-	"strconv"
-	"strings"
-)
+import "strings"
 
 // ApproxEdit is a type for edit operations
 type ApproxEdit int
@@ -13,116 +8,238 @@ type ApproxEdit int
 // EditOps is a type for a sequence of edit operations
 type EditOps = []ApproxEdit
 
-// Approximative matching edit operations.
+// Approximative matching edit operations. The search algorithms only
+// use the first three, but we can read and write all the operations
+// that SAM files use.
 const (
-	Match ApproxEdit  = iota // Match/mismatch operations
-	Insert            = iota // Insertion operations
-	Delete            = iota // Deletion operations
+	Match    ApproxEdit = iota // Match/mismatch operations
+	Insert                     // Insertion operations
+	Delete                     // Deletion operations
+	SeqMatch                   // Matches where the letters are the same (=)
+	Mismatch                   // Matches where the letters differ (X)
+	SoftClip                   // Read letters that are not in the alignment (S)
+	HardClip                   // Read letters that are not in the read either (H)
+	Skip                       // Skipped reference letters, e.g., introns (N)
+	Pad                        // Padding, a gap in both the reference and the read (P)
 )
 
 var editsToString = map[ApproxEdit]string{ //nolint:gochecknoglobals // a constant map
 	Match: "M", Insert: "I", Delete: "D",
+	SeqMatch: "=", Mismatch: "X",
+	SoftClip: "S", HardClip: "H", Skip: "N", Pad: "P",
 }
 
 var stringToEdits = map[string]ApproxEdit{ //nolint:gochecknoglobals // a constant map
 	"M": Match, "I": Insert, "D": Delete,
+	"=": SeqMatch, "X": Mismatch,
+	"S": SoftClip, "H": HardClip, "N": Skip, "P": Pad,
 }
 
-// OpsToCigar turns a list of ops into a cigar.
-func OpsToCigar(ops EditOps) string {
-	var (
-		res  = []string{}
-		i, j int
-	)
-
-	for ; i < len(ops); i = j {
-		for j = i + 1; j < len(ops) ; j++ { 
-			if ops[j] != ops[i] {
-				break
-			}
-		}
+// consumesRef tells us if op covers a letter in the reference.
+func (op ApproxEdit) consumesRef() bool {
+	switch op {
+	case Match, Delete, SeqMatch, Mismatch, Skip:
+		return true
+	default:
+		return false
+	}
+}
 
-		res = append(res, fmt.Sprintf("%d%s", j-i, editsToString[ops[i]]))
+// consumesRead tells us if op covers a letter in the read.
+func (op ApproxEdit) consumesRead() bool {
+	switch op {
+	case Match, Insert, SeqMatch, Mismatch, SoftClip:
+		return true
+	default:
+		return false
 	}
+}
 
-	return strings.Join(res, "")
+// OpsToCigar turns a list of ops into a cigar.
+func OpsToCigar(ops EditOps) string {
+	return CigarFromOps(ops).String()
 }
 
 // CigarToOps turns a cigar string into the list of edit ops.
 func CigarToOps(cigar string) (EditOps, error) {
-	r := regexp.MustCompile(`\d+[MID]`)
-	ops := EditOps{}
-
-	// This check is really inefficient, but I don't have time to
-	// implement a better parser of cigars right now. A scan from
-	// the beginning that chumps of digits would be a lot faster.
-	// I'll leave that for later...
-	for _, s := range r.Split(cigar, -1) {
-		if s != "" {
-			return ops, &InvalidCigar{x: cigar}
-		}
-	}
-
-	for _, op := range r.FindAllString(cigar, -1) {
-		rep, _ := strconv.Atoi(op[:len(op)-1])
-		opcode := stringToEdits[string(op[len(op)-1])]
-
-		for i := 0; i < rep; i++ {
-			ops = append(ops, opcode)
-		}
+	c, err := ParseCigar(cigar)
+	if err != nil {
+		return EditOps{}, err
 	}
 
-	return ops, nil
+	return c.Ops(), nil
 }
 
 // ExtractAlignment extracts a pairwise alignment from the reference, x,
-// the read, p, the position and the edits cigar.
+// the read, p, the position and the edits cigar. Matches and mismatches,
+// whether written as M, = or X, align a letter from each string, an
+// insertion is a letter from p against a gap, and a deletion a letter
+// from x against a gap. Padding is a gap in both. Soft clipped letters
+// in p and skipped regions in x are not part of the alignment, so we
+// leave them out, and hard clipped letters are not in p at all.
 func ExtractAlignment(x, p string, pos int, cigar string) (subx, subp string, err error) {
-	i, j := pos, 0
-
-	ops, err := CigarToOps(cigar)
+	c, err := ParseCigar(cigar)
 	if err != nil {
 		return "", "", err
 	}
 
-	for _, op := range ops {
-		switch op {
-		case Match:
-			subx += string(x[i])
-			subp += string(p[j])
-			i++
-			j++
+	subx, subp = c.Extract(x, p, pos)
+
+	return subx, subp, nil
+}
+
+// Extract extracts the pairwise alignment the cigar describes, of the
+// read p against the reference x at pos. See ExtractAlignment.
+func (c Cigar) Extract(x, p string, pos int) (subx, subp string) {
+	var (
+		bx, bp strings.Builder
+		i, j   = pos, 0
+	)
+
+	for _, run := range c {
+		n := run.Len
+
+		switch run.Op {
+		case Match, SeqMatch, Mismatch:
+			bx.WriteString(x[i : i+n])
+			bp.WriteString(p[j : j+n])
+			i, j = i+n, j+n
 
 		case Insert:
-			subx += "-"
-			subp += string(p[j])
-			j++
+			bx.WriteString(strings.Repeat("-", n))
+			bp.WriteString(p[j : j+n])
+			j += n
 
 		case Delete:
-			subx += string(x[i])
-			subp += "-"
-			i++
+			bx.WriteString(x[i : i+n])
+			bp.WriteString(strings.Repeat("-", n))
+			i += n
+
+		case Pad:
+			bx.WriteString(strings.Repeat("-", n))
+			bp.WriteString(strings.Repeat("-", n))
+
+		case SoftClip:
+			j += n
+
+		case Skip:
+			i += n
+
+		case HardClip:
+			// not in either string
 		}
 	}
 
-	return subx, subp, nil
+	return bx.String(), bp.String()
 }
 
 // CountEdits counts the number of edits in the local alignment between x and p
 // specified by pos and cigar
 func CountEdits(x, p string, pos int, cigar string) (int, error) {
-	edits := 0
-
-	subx, subp, err := ExtractAlignment(x, p, pos, cigar)
+	c, err := ParseCigar(cigar)
 	if err != nil {
 		return 0, err
 	}
 
-	for i := range subx {
-		if subx[i] != subp[i] {
-			edits++
+	return c.CountEdits(x, p, pos), nil
+}
+
+// CountEdits counts the number of edits in the alignment of p against x
+// at pos that the cigar describes: the insertions and deletions, and
+// the aligned letters that differ. It doesn't build the alignment
+// strings.
+func (c Cigar) CountEdits(x, p string, pos int) int {
+	edits, i, j := 0, pos, 0
+
+	for _, run := range c {
+		n := run.Len
+
+		switch run.Op {
+		case Match, SeqMatch, Mismatch:
+			for k := 0; k < n; k++ {
+				if x[i+k] != p[j+k] {
+					edits++
+				}
+			}
+
+			i, j = i+n, j+n
+
+		case Insert:
+			edits += n
+			j += n
+
+		case Delete:
+			edits += n
+			i += n
+
+		case SoftClip:
+			j += n
+
+		case Skip:
+			i += n
+
+		case Pad, HardClip:
+			// gaps against gaps, or letters we don't have
 		}
 	}
 
-	return edits, nil
+	return edits
+}
+
+// ExtendedCigar rewrites cigar, an alignment of p against x at pos, so
+// it uses = for the matches and X for the mismatches instead of M.
+func ExtendedCigar(x, p string, pos int, cigar string) (string, error) {
+	c, err := ParseCigar(cigar)
+	if err != nil {
+		return "", err
+	}
+
+	res := Cigar{}
+	i, j := pos, 0
+
+	for _, run := range c {
+		if run.Op != Match && run.Op != SeqMatch && run.Op != Mismatch {
+			res = append(res, run)
+		} else {
+			for k := 0; k < run.Len; k++ {
+				op := SeqMatch
+				if x[i+k] != p[j+k] {
+					op = Mismatch
+				}
+
+				res = append(res, CigarOp{Op: op, Len: 1})
+			}
+		}
+
+		if run.Op.consumesRef() {
+			i += run.Len
+		}
+
+		if run.Op.consumesRead() {
+			j += run.Len
+		}
+	}
+
+	return res.Merge().String(), nil
+}
+
+// SimpleCigar rewrites cigar so it uses M for both matches and
+// mismatches instead of = and X.
+func SimpleCigar(cigar string) (string, error) {
+	c, err := ParseCigar(cigar)
+	if err != nil {
+		return "", err
+	}
+
+	res := make(Cigar, len(c))
+
+	for k, run := range c {
+		if run.Op == SeqMatch || run.Op == Mismatch {
+			run.Op = Match
+		}
+
+		res[k] = run
+	}
+
+	return res.Merge().String(), nil
 }
@@ -42,6 +42,13 @@ func TestOpsToCigar(t *testing.T) {
 				gostr.Insert, gostr.Insert, gostr.Match, gostr.Match, gostr.Match, gostr.Delete, gostr.Delete, gostr.Insert}},
 			"2I3M2D1I",
 		},
+		{
+			"Extended ops",
+			args{ops: []gostr.ApproxEdit{
+				gostr.HardClip, gostr.SoftClip, gostr.SeqMatch, gostr.SeqMatch, gostr.Mismatch,
+				gostr.Skip, gostr.Skip, gostr.Pad, gostr.Match}},
+			"1H1S2=1X2N1P1M",
+		},
 	}
 
 	for _, tt := range tests {
@@ -90,10 +97,21 @@ func TestCigarToOps(t *testing.T) {
 				gostr.Insert, gostr.Insert},
 			nil,
 		},
+		{
+			"2S1=1X1N1P1H",
+			gostr.EditOps{gostr.SoftClip, gostr.SoftClip, gostr.SeqMatch, gostr.Mismatch,
+				gostr.Skip, gostr.Pad, gostr.HardClip},
+			nil,
+		},
 		{
 			"invalid",
 			gostr.EditOps{},
-			gostr.NewInvalidCigar("invalid"),
+			gostr.NewInvalidCigar("invalid", 0),
+		},
+		{
+			"1M2Q",
+			gostr.EditOps{},
+			gostr.NewInvalidCigar("1M2Q", 3),
 		},
 	}
 
@@ -108,7 +126,7 @@ func TestCigarToOps(t *testing.T) {
 				t.Errorf("CigarToOps() = %v, want %v", got, tt.want)
 			}
 
-			if gotErr != nil && gotErr.Error() != "invalid cigar: "+tt.cigar {
+			if gotErr != nil && gotErr.Error() != tt.wantErr.Error() {
 				t.Errorf("Unexpected error message: %s", gotErr)
 			}
 		})
@@ -150,11 +168,35 @@ func TestExtractAlignment(t *testing.T) {
 			"gt-ac", "gtaac",
 			nil,
 		},
+		{
+			"Sequence matches and mismatches",
+			args{"acgtacgt", "gtcc", 2, "2=1X1="},
+			"gtac", "gtcc",
+			nil,
+		},
+		{
+			"Clipping",
+			args{"acgtacgt", "ttgtac", 2, "2H2S4M1H"},
+			"gtac", "gtac",
+			nil,
+		},
+		{
+			"Skipped region",
+			args{"acgtacgt", "gtgt", 2, "2M2N2M"},
+			"gtgt", "gtgt",
+			nil,
+		},
+		{
+			"Padding",
+			args{"acgtacgt", "gtaac", 2, "2M1P1I2M"},
+			"gt--ac", "gt-aac",
+			nil,
+		},
 		{
 			"Invalid",
 			args{"acgtacgt", "gtaac", 2, "invalid"},
 			"", "",
-			gostr.NewInvalidCigar("invalid"),
+			gostr.NewInvalidCigar("invalid", 0),
 		},
 	}
 
@@ -210,11 +252,29 @@ func TestCountEdits(t *testing.T) {
 			1, // "gt-ac", "gtaac",
 			nil,
 		},
+		{
+			"Mismatch",
+			args{"acgtacgt", "gtcc", 2, "2=1X1="},
+			1, // "gtac", "gtcc"
+			nil,
+		},
+		{
+			"Clipping and skipping",
+			args{"acgtacgt", "aagtgt", 2, "2S2M2N2M"},
+			0, // "gtgt", "gtgt"
+			nil,
+		},
+		{
+			"Padding",
+			args{"acgtacgt", "gtaac", 2, "2M1P1I2M"},
+			1, // "gt--ac", "gt-aac"
+			nil,
+		},
 		{
 			"Invalid",
 			args{"acgtacgt", "gtaac", 2, "invalid"},
 			0, // error...
-			gostr.NewInvalidCigar("invalid"),
+			gostr.NewInvalidCigar("invalid", 0),
 		},
 	}
 
@@ -233,6 +293,58 @@ func TestCountEdits(t *testing.T) {
 	}
 }
 
+func TestExtendedCigar(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		x, p     string
+		pos      int
+		cigar    string
+		extended string
+		simple   string
+	}{
+		{"acgtacgt", "gtac", 2, "4M", "4=", "4M"},
+		{"acgtacgt", "gtcc", 2, "4M", "2=1X1=", "4M"},
+		{"acgtacgt", "gtcc", 2, "2=1X1=", "2=1X1=", "4M"},
+		{"acgtacgt", "ttgtcc", 2, "1H2S2M1D2M", "1H2S2=1D1=1X", "1H2S2M1D2M"},
+		{"acgtacgt", "aagt", 2, "2M2N2M", "2X2N2=", "2M2N2M"},
+		{"acgtacgt", "gtaac", 2, "2M1P1I2M", "2=1P1I2=", "2M1P1I2M"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.cigar, func(t *testing.T) {
+			t.Parallel()
+
+			extended, err := gostr.ExtendedCigar(tt.x, tt.p, tt.pos, tt.cigar)
+			if err != nil || extended != tt.extended {
+				t.Errorf("ExtendedCigar() = %q (%v), want %q", extended, err, tt.extended)
+			}
+
+			simple, err := gostr.SimpleCigar(extended)
+			if err != nil || simple != tt.simple {
+				t.Errorf("SimpleCigar() = %q (%v), want %q", simple, err, tt.simple)
+			}
+
+			// The two styles describe the same alignment
+			subx, subp, _ := gostr.ExtractAlignment(tt.x, tt.p, tt.pos, tt.cigar)
+			extx, extp, _ := gostr.ExtractAlignment(tt.x, tt.p, tt.pos, extended)
+
+			if subx != extx || subp != extp {
+				t.Errorf("%q and %q give different alignments", tt.cigar, extended)
+			}
+		})
+	}
+
+	if _, err := gostr.ExtendedCigar("acgt", "acgt", 0, "foo"); !errors.Is(err, gostr.NewInvalidCigar("foo", 0)) {
+		t.Errorf("Expected an invalid cigar error, got %v", err)
+	}
+
+	if _, err := gostr.SimpleCigar("foo"); !errors.Is(err, gostr.NewInvalidCigar("foo", 0)) {
+		t.Errorf("Expected an invalid cigar error, got %v", err)
+	}
+}
+
 type approxAlgo = func(string) func(string, int, func(int, string))
 
 var approxAlgorithms = map[string]approxAlgo{ //nolint:gochecknoglobals // I'm fine with a global here
@@ -0,0 +1,76 @@
+package gostr
+
+// Bidirectional FM-index (2BWT). The approximative tables have an
+// o-table for the BWT of x (Otab) and one for the BWT of x reversed
+// (Rotab), and since the two strings have the same letters, they share
+// the c-table. An occurrence of a pattern P then has an interval in
+// the suffix array of x, of the suffixes that start with P, and one of
+// the same size in the suffix array of x reversed, of the suffixes that
+// start with P reversed. If we keep the two in sync, we can extend P to
+// the left with the first and to the right with the second.
+
+// biInterval is a pair of synchronised intervals, [left,left+size) in
+// the suffix array of x and [rleft,rleft+size) in the suffix array of
+// x reversed.
+type biInterval struct {
+	left, rleft, size int
+}
+
+// fullBiInterval returns the interval of the empty string.
+func (tbls *FMIndexTables) fullBiInterval() biInterval {
+	return biInterval{left: 0, rleft: 0, size: tbls.saLen()}
+}
+
+// letterCounts puts the number of occurrences of each letter, except
+// the sentinel, in bwt[left:right] into counts, using the rank table
+// for the bwt.
+func (tbls *FMIndexTables) letterCounts(rank RankTable, left, right int, counts []int) {
+	for b := 1; b < tbls.Alpha.Size(); b++ {
+		counts[b] = rank.Rank(byte(b), right) - rank.Rank(byte(b), left)
+	}
+}
+
+// biExtend calls fn for each letter a that we can extend the interval
+// with, together with the new interval. If left is true, we extend to
+// the left, using Otab, and otherwise to the right, using Rotab.
+//
+// In the table we extend with, the new interval is the usual backward
+// search step. In the other table, the new interval is the part of
+// the old interval where the letter after the (reversed) pattern is a,
+// which comes after the parts for the letters smaller than a, including
+// the sentinel, and those are the letters we count in the bwt.
+func (tbls *FMIndexTables) biExtend(iv biInterval, left bool, counts []int, fn func(a byte, next biInterval)) {
+	rank, from, other := tbls.Rotab, iv.rleft, iv.left
+	if left {
+		rank, from, other = tbls.Otab, iv.left, iv.rleft
+	}
+
+	tbls.letterCounts(rank, from, from+iv.size, counts)
+
+	// The letters we have counted so far, starting with the sentinel,
+	// which is whatever the other letters don't account for.
+	smaller := iv.size
+
+	for b := 1; b < tbls.Alpha.Size(); b++ {
+		smaller -= counts[b]
+	}
+
+	for b := 1; b < tbls.Alpha.Size(); b++ {
+		a := byte(b)
+
+		if counts[b] > 0 {
+			next := biInterval{size: counts[b]}
+			start := tbls.Ctab.Rank(a) + rank.Rank(a, from)
+
+			if left {
+				next.left, next.rleft = start, other+smaller
+			} else {
+				next.left, next.rleft = other+smaller, start
+			}
+
+			fn(a, next)
+		}
+
+		smaller += counts[b]
+	}
+}
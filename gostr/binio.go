@@ -0,0 +1,249 @@
+package gostr
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"strconv"
+	"unsafe"
+)
+
+// Helpers for the little-endian binary formats we use to store indices
+// on disk. The writer and decoder report problems by panicking with an
+// error (through checkError), so use catchError in the functions that
+// call them.
+
+const (
+	binAlign     = 8         // all arrays start at an offset that is a multiple of this
+	binChunkSize = 64 * 1024 // bytes we encode at a time when writing arrays
+)
+
+func paddingFor(n int64) int64 {
+	return (binAlign - n%binAlign) % binAlign
+}
+
+// binWriter writes little-endian values to a writer and counts how
+// many bytes it has written.
+type binWriter struct {
+	w   io.Writer
+	n   int64
+	buf []byte
+}
+
+func newBinWriter(w io.Writer) *binWriter {
+	return &binWriter{w: w, buf: make([]byte, binChunkSize)}
+}
+
+func (bw *binWriter) write(b []byte) {
+	n, err := bw.w.Write(b)
+	bw.n += int64(n)
+	checkError(err)
+}
+
+func (bw *binWriter) uint32(v uint32) {
+	binary.LittleEndian.PutUint32(bw.buf, v)
+	bw.write(bw.buf[:4])
+}
+
+func (bw *binWriter) uint64(v uint64) {
+	binary.LittleEndian.PutUint64(bw.buf, v)
+	bw.write(bw.buf[:8])
+}
+
+func (bw *binWriter) int(v int) {
+	bw.uint64(uint64(v))
+}
+
+// pad writes zeros until we are at an aligned offset.
+func (bw *binWriter) pad() {
+	if p := paddingFor(bw.n); p > 0 {
+		for i := range bw.buf[:p] {
+			bw.buf[i] = 0
+		}
+
+		bw.write(bw.buf[:p])
+	}
+}
+
+// int32s writes a length-prefixed array of int32.
+func (bw *binWriter) int32s(xs []int32) {
+	const size = 4
+
+	bw.int(len(xs))
+
+	for len(xs) > 0 {
+		chunk := smallest(len(xs), len(bw.buf)/size)
+		for i, x := range xs[:chunk] {
+			binary.LittleEndian.PutUint32(bw.buf[size*i:], uint32(x))
+		}
+
+		bw.write(bw.buf[:size*chunk])
+		xs = xs[chunk:]
+	}
+
+	bw.pad()
+}
+
+// ints writes a length-prefixed array of int, as 64-bit integers.
+func (bw *binWriter) ints(xs []int) {
+	const size = 8
+
+	bw.int(len(xs))
+
+	for len(xs) > 0 {
+		chunk := smallest(len(xs), len(bw.buf)/size)
+		for i, x := range xs[:chunk] {
+			binary.LittleEndian.PutUint64(bw.buf[size*i:], uint64(x))
+		}
+
+		bw.write(bw.buf[:size*chunk])
+		xs = xs[chunk:]
+	}
+}
+
+// uint64s writes a length-prefixed array of uint64.
+func (bw *binWriter) uint64s(xs []uint64) {
+	const size = 8
+
+	bw.int(len(xs))
+
+	for len(xs) > 0 {
+		chunk := smallest(len(xs), len(bw.buf)/size)
+		for i, x := range xs[:chunk] {
+			binary.LittleEndian.PutUint64(bw.buf[size*i:], x)
+		}
+
+		bw.write(bw.buf[:size*chunk])
+		xs = xs[chunk:]
+	}
+}
+
+// binDecoder decodes little-endian values from a byte slice. Reading
+// past the end of the slice is reported as a CorruptIndex error.
+//
+// If alias is set, the arrays we decode point into the data instead
+// of being copies, when the machine's byte order and the alignment of
+// the data allow it. That way, we can use arrays in memory-mapped files
+// without loading them onto the heap.
+type binDecoder struct {
+	data  []byte
+	pos   int
+	alias bool
+}
+
+// nativeLittleEndian is true if the machine stores integers in little-endian
+// byte order, so we can use the encoded arrays directly.
+var nativeLittleEndian = func() bool { //nolint:gochecknoglobals // a constant we compute at startup
+	x := uint16(1)
+	return *(*byte)(unsafe.Pointer(&x)) == 1
+}()
+
+// canAlias tells us if we can view raw as an array with elements of
+// the given size.
+func (d *binDecoder) canAlias(raw []byte, size int) bool {
+	return d.alias && nativeLittleEndian && len(raw) > 0 &&
+		uintptr(unsafe.Pointer(&raw[0]))%uintptr(size) == 0
+}
+
+func (d *binDecoder) next(n int) []byte {
+	if n < 0 || n > len(d.data)-d.pos {
+		checkError(NewCorruptIndex("section is too short for its content"))
+	}
+
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+
+	return b
+}
+
+func (d *binDecoder) uint64() uint64 {
+	return binary.LittleEndian.Uint64(d.next(8)) //nolint:gomnd // 8 bytes in a uint64
+}
+
+func (d *binDecoder) int() int {
+	v := d.uint64()
+	if v > uint64(math.MaxInt) {
+		checkError(NewCorruptIndex("integer value out of range"))
+	}
+
+	return int(v)
+}
+
+// count reads the length of an array with elements of the given size
+// and checks that the array can fit in the rest of the data.
+func (d *binDecoder) count(size int) int {
+	n := d.int()
+	if n > (len(d.data)-d.pos)/size {
+		checkError(NewCorruptIndex("array is longer than its section"))
+	}
+
+	return n
+}
+
+func (d *binDecoder) align() {
+	d.next(int(paddingFor(int64(d.pos))))
+}
+
+// done checks that we have consumed all the data.
+func (d *binDecoder) done() {
+	if d.pos != len(d.data) {
+		checkError(NewCorruptIndex("unexpected data at the end of section"))
+	}
+}
+
+func (d *binDecoder) int32s() []int32 {
+	const size = 4
+
+	n := d.count(size)
+	raw := d.next(size * n)
+
+	if d.canAlias(raw, size) {
+		d.align()
+		return unsafe.Slice((*int32)(unsafe.Pointer(&raw[0])), n)
+	}
+
+	xs := make([]int32, n)
+	for i := range xs {
+		xs[i] = int32(binary.LittleEndian.Uint32(raw[size*i:]))
+	}
+
+	d.align()
+
+	return xs
+}
+
+func (d *binDecoder) ints() []int {
+	const size = 8
+
+	n := d.count(size)
+	raw := d.next(size * n)
+
+	if strconv.IntSize == bytebits*size && d.canAlias(raw, size) {
+		return unsafe.Slice((*int)(unsafe.Pointer(&raw[0])), n)
+	}
+
+	xs := make([]int, n)
+	for i := range xs {
+		xs[i] = int(binary.LittleEndian.Uint64(raw[size*i:]))
+	}
+
+	return xs
+}
+
+func (d *binDecoder) uint64s() []uint64 {
+	const size = 8
+
+	n := d.count(size)
+	raw := d.next(size * n)
+
+	if d.canAlias(raw, size) {
+		return unsafe.Slice((*uint64)(unsafe.Pointer(&raw[0])), n)
+	}
+
+	xs := make([]uint64, n)
+	for i := range xs {
+		xs[i] = binary.LittleEndian.Uint64(raw[size*i:])
+	}
+
+	return xs
+}
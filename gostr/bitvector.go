@@ -0,0 +1,151 @@
+package gostr
+
+import "math/bits"
+
+const (
+	wordBits = 64
+
+	// The default block size (in words) for rank bit vectors. With
+	// eight words per block we store a 32-bit checkpoint for every
+	// 512 bits.
+	defaultBlockWords = 8
+)
+
+// rankBitVector is a bit vector that supports rank queries. The bits
+// are packed in 64-bit words, and for every block of blockWords words
+// we store a checkpoint with the number of set bits before the block.
+// A rank query is then a checkpoint lookup plus popcounts over at most
+// blockWords words.
+type rankBitVector struct {
+	length      int
+	blockWords  int
+	words       []uint64
+	checkpoints []int32
+}
+
+// newRankBitVector creates a bit vector of the given length with all bits
+// cleared. Set the bits you need and then call buildRanks before you
+// use rank.
+func newRankBitVector(length, blockWords int) *rankBitVector {
+	if blockWords < 1 {
+		blockWords = 1 // we need at least one word per block
+	}
+
+	nwords := (length + wordBits - 1) / wordBits
+
+	return &rankBitVector{
+		length:      length,
+		blockWords:  blockWords,
+		words:       make([]uint64, nwords),
+		checkpoints: make([]int32, nwords/blockWords+1),
+	}
+}
+
+func (bv *rankBitVector) get(i int) bool {
+	return bv.words[i/wordBits]&(1<<(i%wordBits)) != 0
+}
+
+func (bv *rankBitVector) set(i int) {
+	bv.words[i/wordBits] |= 1 << (i % wordBits)
+}
+
+// buildRanks computes the checkpoints. It must be called after the
+// bits are set and before rank queries.
+func (bv *rankBitVector) buildRanks() {
+	var count int32
+
+	for w, word := range bv.words {
+		if w%bv.blockWords == 0 {
+			bv.checkpoints[w/bv.blockWords] = count
+		}
+
+		count += int32(bits.OnesCount64(word))
+	}
+
+	// The last checkpoint might not have been reached if the
+	// number of words is a multiple of the block size.
+	if len(bv.words)%bv.blockWords == 0 {
+		bv.checkpoints[len(bv.words)/bv.blockWords] = count
+	}
+}
+
+// rank returns the number of set bits in the interval [0,i).
+func (bv *rankBitVector) rank(i int) int {
+	block := i / (bv.blockWords * wordBits)
+	res := int(bv.checkpoints[block])
+
+	last := i / wordBits
+	for w := block * bv.blockWords; w < last; w++ {
+		res += bits.OnesCount64(bv.words[w])
+	}
+
+	if i%wordBits != 0 {
+		mask := uint64(1)<<(i%wordBits) - 1
+		res += bits.OnesCount64(bv.words[last] & mask)
+	}
+
+	return res
+}
+
+// select1 returns the index of the k'th set bit (counting from zero),
+// or -1 if there are not that many set bits.
+func (bv *rankBitVector) select1(k int) int {
+	return bv.selectBit(k, func(block int) int {
+		return int(bv.checkpoints[block])
+	}, func(word uint64) uint64 {
+		return word
+	})
+}
+
+// select0 returns the index of the k'th cleared bit (counting from zero),
+// or -1 if there are not that many cleared bits.
+func (bv *rankBitVector) select0(k int) int {
+	blockBits := bv.blockWords * wordBits
+
+	return bv.selectBit(k, func(block int) int {
+		// The last block can contain padding bits, so we cap at the length
+		return smallest(block*blockBits, bv.length) - int(bv.checkpoints[block])
+	}, func(word uint64) uint64 {
+		return ^word
+	})
+}
+
+// selectBit does the work for select0 and select1. The count function
+// gives the number of relevant bits before a block and the mask function
+// flips a word, if necessary, so the bits we count are the set bits.
+func (bv *rankBitVector) selectBit(k int, count func(block int) int, mask func(uint64) uint64) int {
+	// Binary search for the last block with fewer than k+1 bits before it.
+	lo, hi := 0, len(bv.checkpoints)-1
+	for lo < hi {
+		mid := (lo + hi + 1) / 2 //nolint:gomnd // 2 is just half, not magic
+		if count(mid) <= k {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+
+	k -= count(lo)
+
+	for w := lo * bv.blockWords; w < len(bv.words); w++ {
+		word := mask(bv.words[w])
+		if w == len(bv.words)-1 && bv.length%wordBits != 0 {
+			// Don't count the padding bits in the last word
+			word &= uint64(1)<<(bv.length%wordBits) - 1
+		}
+
+		ones := bits.OnesCount64(word)
+		if k < ones {
+			// The bit is in this word; remove the k lowest set bits
+			for ; k > 0; k-- {
+				word &= word - 1
+			}
+
+			return w*wordBits + bits.TrailingZeros64(word)
+		}
+
+		k -= ones
+	}
+
+	return -1
+}
@@ -0,0 +1,84 @@
+package gostr
+
+import (
+	"testing"
+
+	"github.com/mailund/gostr/testutils"
+)
+
+func Test_rankBitVector(t *testing.T) {
+	rng := testutils.NewRandomSeed(t)
+
+	for _, n := range []int{0, 1, 63, 64, 65, 511, 512, 513, 2000} {
+		for _, blockWords := range []int{0, 1, 3, 8} {
+			bv := newRankBitVector(n, blockWords)
+			bits := make([]bool, n)
+
+			for i := range bits {
+				if rng.Intn(2) == 1 {
+					bits[i] = true
+					bv.set(i)
+				}
+			}
+
+			bv.buildRanks()
+
+			count := 0
+
+			for i := 0; i <= n; i++ {
+				if got := bv.rank(i); got != count {
+					t.Fatalf("rank(%d) = %d with n = %d and %d words per block, expected %d",
+						i, got, n, blockWords, count)
+				}
+
+				if i < n {
+					if bv.get(i) != bits[i] {
+						t.Fatalf("get(%d) = %v, expected %v", i, bv.get(i), bits[i])
+					}
+
+					if bits[i] {
+						count++
+					}
+				}
+			}
+		}
+	}
+}
+
+func Test_rankBitVectorSelect(t *testing.T) {
+	rng := testutils.NewRandomSeed(t)
+
+	for _, n := range []int{0, 1, 63, 64, 65, 511, 512, 513, 2000} {
+		for _, blockWords := range []int{1, 3, 8} {
+			bv := newRankBitVector(n, blockWords)
+			ones, zeros := []int{}, []int{}
+
+			for i := 0; i < n; i++ {
+				if rng.Intn(2) == 1 {
+					bv.set(i)
+					ones = append(ones, i)
+				} else {
+					zeros = append(zeros, i)
+				}
+			}
+
+			bv.buildRanks()
+
+			for k, i := range ones {
+				if got := bv.select1(k); got != i {
+					t.Fatalf("select1(%d) = %d, expected %d (n = %d)", k, got, i, n)
+				}
+			}
+
+			for k, i := range zeros {
+				if got := bv.select0(k); got != i {
+					t.Fatalf("select0(%d) = %d, expected %d (n = %d)", k, got, i, n)
+				}
+			}
+
+			if bv.select1(len(ones)) != -1 || bv.select0(len(zeros)) != -1 {
+				t.Errorf("Selecting past the last bit should give -1 (n = %d)", n)
+			}
+		}
+	}
+}
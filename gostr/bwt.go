@@ -54,6 +54,15 @@ func NewCTab(bwt []byte, asize int) *CTab {
 	return &CTab{counts}
 }
 
+// RankTable is the interface for o-tables (rank tables) over a BWT
+// string. The FM-index searches only need rank queries, so they work
+// with any of the structures that implement it.
+type RankTable interface {
+	// Rank How many times do we see letter a before index i
+	// in the BWT string? Undefined behaviour for the sentinel.
+	Rank(a byte, i int) int
+}
+
 // OTab Holds the o-table (rank table) from a BWT string
 type OTab struct {
 	nrow, ncol int
@@ -101,8 +110,11 @@ func NewOTab(bwt []byte, asize int) *OTab {
 	otab := OTab{nrow, ncol, table}
 
 	// The character at the beginning of bwt gets a count
-	// of one at row one.
-	otab.set(bwt[0], 1, 1)
+	// of one at row one. If it is the sentinel, the string
+	// was empty and there is nothing to count.
+	if bwt[0] != 0 {
+		otab.set(bwt[0], 1, 1)
+	}
 
 	// The remaining entries either copies or increment from
 	// the previous column. We count a from 1 to alpha size
@@ -140,9 +152,14 @@ func countLetters(x []byte) int {
 
 // ReverseBwt reconstructs the original string from the bwt string.
 func ReverseBwt(bwt []byte) []byte {
-	asize := countLetters(bwt)
-	ctab := NewCTab(bwt, asize)
-	otab := NewOTab(bwt, asize)
+	return ReverseBwtWithOTab(bwt, NewOTab(bwt, countLetters(bwt)))
+}
+
+// ReverseBwtWithOTab reconstructs the original string from the bwt string,
+// using otab for the rank queries. This lets you use a SampledOTab, or
+// another compact rank table, instead of the full OTab.
+func ReverseBwtWithOTab(bwt []byte, otab RankTable) []byte {
+	ctab := NewCTab(bwt, countLetters(bwt))
 
 	x := make([]byte, len(bwt))
 	i := 0
@@ -167,43 +184,160 @@ type FMIndexTables struct {
 	Alpha *Alphabet
 	Sa    []int32
 	Ctab  *CTab
-	Otab  *OTab
+	Otab  RankTable
+
+	// If the suffix array is sampled, Sa is nil and we
+	// have the sampled suffix array here instead.
+	Ssa *SampledSA
 
 	// for approx matching
-	Rotab *OTab
+	Rotab RankTable
+}
+
+// FMIndexOption is an option you can give to the FM-index
+// table builders.
+type FMIndexOption func(*fmIndexConfig)
+
+type fmIndexConfig struct {
+	saSampling   int
+	otabSampling int
+	wavelet      bool
+}
+
+func newFMIndexConfig(opts []FMIndexOption) *fmIndexConfig {
+	cfg := &fmIndexConfig{saSampling: 1}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return cfg
+}
+
+// newOTab builds the o-table the configuration asks for.
+func (cfg *fmIndexConfig) newOTab(bwt []byte, asize int) RankTable {
+	if cfg.wavelet {
+		return NewWaveletMatrix(bwt, asize)
+	}
+
+	if cfg.otabSampling > 0 {
+		return NewSampledOTab(bwt, asize, cfg.otabSampling)
+	}
+
+	return NewOTab(bwt, asize)
+}
+
+// WithSASampling makes the builders keep only the suffix array entries
+// for every k'th text position. The rest are recovered with LF-mapping
+// when we report hits, so a larger k uses less memory but makes
+// reporting slower. A k of one (or less) keeps the full suffix array.
+func WithSASampling(k int) FMIndexOption {
+	return func(cfg *fmIndexConfig) {
+		cfg.saSampling = k
+	}
+}
+
+// WithOTabSampling makes the builders use a SampledOTab, with checkpoints
+// every k positions, for the o-tables instead of the full OTab.
+func WithOTabSampling(k int) FMIndexOption {
+	return func(cfg *fmIndexConfig) {
+		cfg.otabSampling = k
+	}
+}
+
+// WithWaveletOTab makes the builders use a WaveletMatrix for the o-tables.
+// It is slower than the other o-tables for small alphabets, but its size
+// doesn't depend on the alphabet, so use it for large alphabets.
+func WithWaveletOTab() FMIndexOption {
+	return func(cfg *fmIndexConfig) {
+		cfg.wavelet = true
+	}
 }
 
 // BuildFMIndexExactTables builds the preprocessing tables for exact FM-index
 // searching.
-func BuildFMIndexExactTables(x string) *FMIndexTables {
+func BuildFMIndexExactTables(x string, opts ...FMIndexOption) *FMIndexTables {
+	cfg := newFMIndexConfig(opts)
+
 	xb, alpha := MapStringWithSentinel(x)
 	sa, _ := SaisWithAlphabet(x, alpha)
 	bwt := Bwt(xb, sa)
 	ctab := NewCTab(bwt, alpha.Size())
-	otab := NewOTab(bwt, alpha.Size())
+	otab := cfg.newOTab(bwt, alpha.Size())
 
-	return &FMIndexTables{
+	tbls := &FMIndexTables{
 		Alpha: alpha,
 		Sa:    sa,
 		Ctab:  ctab,
 		Otab:  otab,
 	}
+
+	if cfg.saSampling > 1 {
+		tbls.Sa = nil
+		tbls.Ssa = NewSampledSA(sa, cfg.saSampling)
+	}
+
+	return tbls
 }
 
-// BuildFMIndexExactTables builds the preprocessing tables for exact FM-index
-// searching.
-func BuildFMIndexApproxTables(x string) *FMIndexTables {
-	tbls := BuildFMIndexExactTables(x)
+// BuildFMIndexApproxTables builds the preprocessing tables for approximative
+// FM-index searching.
+func BuildFMIndexApproxTables(x string, opts ...FMIndexOption) *FMIndexTables {
+	cfg := newFMIndexConfig(opts)
+	tbls := BuildFMIndexExactTables(x, opts...)
 
 	// Reverse string x and build the reverse O-table.
-	revx := ReverseString(x)
+	revx := reverseBytes(x)
 	sa, _ := SaisWithAlphabet(revx, tbls.Alpha)
 	revb, _ := tbls.Alpha.MapToBytesWithSentinel(revx)
-	tbls.Rotab = NewOTab(Bwt(revb, sa), tbls.Alpha.Size())
+	tbls.Rotab = cfg.newOTab(Bwt(revb, sa), tbls.Alpha.Size())
 
 	return tbls
 }
 
+// saLen returns the length of the suffix array, sampled or not.
+func (tbls *FMIndexTables) saLen() int {
+	if tbls.Ssa != nil {
+		return tbls.Ssa.Len()
+	}
+
+	return len(tbls.Sa)
+}
+
+// bwtAt recovers the BWT letter at index i from the O-table. If the
+// o-table can give us the letter directly we use that; otherwise we look
+// for the letter whose rank changes at i, and if none does, it must be
+// the sentinel.
+func (tbls *FMIndexTables) bwtAt(i int) byte {
+	if acc, ok := tbls.Otab.(interface{ Access(i int) byte }); ok {
+		return acc.Access(i)
+	}
+
+	for a := 1; a < tbls.Alpha.Size(); a++ {
+		if tbls.Otab.Rank(byte(a), i+1) != tbls.Otab.Rank(byte(a), i) {
+			return byte(a)
+		}
+	}
+
+	return Sentinel
+}
+
+// lf is the LF-mapping: it takes the index of a suffix, i, to the index
+// of the suffix that starts one position earlier in the text.
+func (tbls *FMIndexTables) lf(i int) int {
+	a := tbls.bwtAt(i)
+	return tbls.Ctab.Rank(a) + tbls.Otab.Rank(a, i)
+}
+
+// saLookup returns the suffix array value at index i, recovering it
+// from the sampled suffix array if we do not have the full one.
+func (tbls *FMIndexTables) saLookup(i int) int {
+	if tbls.Ssa != nil {
+		return tbls.Ssa.Lookup(i, tbls.lf)
+	}
+
+	return int(tbls.Sa[i])
+}
+
 // FMIndexExactFromTables returns a search function based
 // on the preprocessed tables
 func FMIndexExactFromTables(tbls *FMIndexTables) func(p string, cb func(i int)) {
@@ -213,7 +347,7 @@ func FMIndexExactFromTables(tbls *FMIndexTables) func(p string, cb func(i int))
 			return // p doesn't fit the alphabet, so we can't match
 		}
 
-		left, right := 0, len(tbls.Sa)
+		left, right := 0, tbls.saLen()
 
 		for i := len(pb) - 1; i >= 0; i-- {
 			a := pb[i]
@@ -226,7 +360,7 @@ func FMIndexExactFromTables(tbls *FMIndexTables) func(p string, cb func(i int))
 		}
 
 		for i := left; i < right; i++ {
-			cb(int(tbls.Sa[i]))
+			cb(tbls.saLookup(i))
 		}
 	}
 }
@@ -241,7 +375,7 @@ func buildDtab(p []byte, tbls *FMIndexTables) []int {
 	dtab := make([]int, len(p))
 
 	minEdits := 0
-	left, right := 0, len(tbls.Sa)
+	left, right := 0, tbls.saLen()
 
 	for i := range p {
 		a := p[i]
@@ -251,7 +385,7 @@ func buildDtab(p []byte, tbls *FMIndexTables) []int {
 		if left >= right {
 			minEdits++
 
-			left, right = 0, len(tbls.Sa)
+			left, right = 0, tbls.saLen()
 		}
 
 		dtab[i] = minEdits
@@ -282,12 +416,12 @@ func revOps(ops *EditOps) EditOps {
 	return rev
 }
 
-func fmApproxReport(left, right int, ops *EditOps, sa *[]int32, cb func(i int, cigar string)) {
+func fmApproxReport(left, right int, ops *EditOps, tbls *FMIndexTables, cb func(i int, cigar string)) {
 	// Reverse the ops because we build them in reverse, then
 	// convert them into a cigar for reporting
 	cigar := OpsToCigar(revOps(ops))
 	for j := left; j < right; j++ {
-		cb(int((*sa)[j]), cigar)
+		cb(tbls.saLookup(j), cigar)
 	}
 }
 
@@ -310,7 +444,7 @@ func FMIndexApproxFromTables(tbls *FMIndexTables) func(p string, edits int, cb f
 		rec = func(i, left, right, edits int) {
 			if i < 0 {
 				if edits >= 0 {
-					fmApproxReport(left, right, &ops, &tbls.Sa, cb)
+					fmApproxReport(left, right, &ops, tbls, cb)
 				}
 
 				return
@@ -320,7 +454,8 @@ func FMIndexApproxFromTables(tbls *FMIndexTables) func(p string, edits int, cb f
 				return // not sufficient edits left
 			}
 
-			for a := byte(1); a < byte(tbls.Alpha.Size()); a++ {
+			for b := 1; b < tbls.Alpha.Size(); b++ {
+				a := byte(b)
 				nextLeft := tbls.Ctab.Rank(a) + tbls.Otab.Rank(a, left)
 				nextRight := tbls.Ctab.Rank(a) + tbls.Otab.Rank(a, right)
 
@@ -348,7 +483,7 @@ func FMIndexApproxFromTables(tbls *FMIndexTables) func(p string, edits int, cb f
 		}
 
 		// finally, fire away with the first recursive call!
-		i, left, right := len(p)-1, 0, len(tbls.Sa)
+		i, left, right := len(p)-1, 0, tbls.saLen()
 		rec(i, left, right, edits)
 	}
 }
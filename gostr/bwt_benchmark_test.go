@@ -0,0 +1,91 @@
+package gostr_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mailund/gostr/gostr"
+	"github.com/mailund/gostr/testutils"
+)
+
+func benchmarkBwt(b *testing.B, n int) (bwt []byte, asize int) {
+	b.Helper()
+
+	rng := testutils.NewRandomSeed(b)
+	x := testutils.RandomStringN(n, "acgt", rng)
+	xb, alpha := gostr.MapStringWithSentinel(x)
+	sa, _ := gostr.SaisWithAlphabet(x, alpha)
+
+	return gostr.Bwt(xb, sa), alpha.Size()
+}
+
+func benchmarkRank(b *testing.B, otab gostr.RankTable, n, asize int) {
+	b.Helper()
+
+	rng := testutils.NewRandomSeed(b)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		otab.Rank(byte(1+rng.Intn(asize-1)), rng.Intn(n+1))
+	}
+}
+
+func Benchmark_OTabRank(b *testing.B) {
+	for _, n := range []int{10000, 1000000} {
+		bwt, asize := benchmarkBwt(b, n)
+
+		b.Run(fmt.Sprintf("OTab:n=%d", n), func(b *testing.B) {
+			benchmarkRank(b, gostr.NewOTab(bwt, asize), n, asize)
+		})
+
+		b.Run(fmt.Sprintf("WaveletMatrix:n=%d", n), func(b *testing.B) {
+			benchmarkRank(b, gostr.NewWaveletMatrix(bwt, asize), n, asize)
+		})
+
+		for _, k := range []int{64, 256, 1024} {
+			otab := gostr.NewSampledOTab(bwt, asize, k)
+
+			b.Run(fmt.Sprintf("SampledOTab:k=%d:n=%d", k, n), func(b *testing.B) {
+				benchmarkRank(b, otab, n, asize)
+			})
+		}
+	}
+}
+
+func Benchmark_OTabConstruction(b *testing.B) {
+	bwt, asize := benchmarkBwt(b, 1000000)
+
+	b.Run("OTab", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			gostr.NewOTab(bwt, asize)
+		}
+	})
+
+	b.Run("SampledOTab", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			gostr.NewSampledOTab(bwt, asize, gostr.DefaultOTabSampling)
+		}
+	})
+}
+
+func Benchmark_FMIndexOTabSearch(b *testing.B) {
+	rng := testutils.NewRandomSeed(b)
+	x := testutils.RandomStringN(100000, "acgt", rng)
+
+	tables := map[string]*gostr.FMIndexTables{
+		"OTab":        gostr.BuildFMIndexApproxTables(x),
+		"SampledOTab": gostr.BuildFMIndexApproxTables(x, gostr.WithOTabSampling(gostr.DefaultOTabSampling)),
+	}
+
+	for name, tbls := range tables {
+		search := gostr.FMIndexApproxFromTables(tbls)
+
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				p := x[i%(len(x)-20) : i%(len(x)-20)+20]
+				search(p, 1, func(int, string) {})
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/gob"
 	"reflect"
+	"sort"
 	"testing"
 
 	"github.com/mailund/gostr/gostr"
@@ -125,3 +126,115 @@ func TestOTabEncoding(t *testing.T) {
 		t.Errorf("These two otables should be equal now")
 	}
 }
+
+func collectExactHits(search func(string, func(int)), p string) []int {
+	hits := []int{}
+	search(p, func(i int) { hits = append(hits, i) })
+	sort.Ints(hits)
+
+	return hits
+}
+
+func TestSampledSAExact(t *testing.T) {
+	rng := testutils.NewRandomSeed(t)
+	testutils.GenerateTestStringsAndPatterns(10, 50, rng,
+		func(x, p string) {
+			full := gostr.FMIndexExactFromTables(gostr.BuildFMIndexExactTables(x))
+			expected := collectExactHits(full, p)
+
+			for _, k := range []int{2, 3, 7, 32} {
+				tbls := gostr.BuildFMIndexExactTables(x, gostr.WithSASampling(k))
+				if tbls.Sa != nil || tbls.Ssa == nil {
+					t.Fatalf("Expected a sampled suffix array with k = %d", k)
+				}
+
+				sampled := gostr.FMIndexExactFromTables(tbls)
+				if hits := collectExactHits(sampled, p); !reflect.DeepEqual(expected, hits) {
+					t.Fatalf("Sampling with k = %d gave hits %v for %q in %q, expected %v",
+						k, hits, p, x, expected)
+				}
+			}
+		})
+}
+
+func TestSampledSAApprox(t *testing.T) {
+	type hit struct {
+		pos   int
+		cigar string
+	}
+
+	collect := func(search func(string, int, func(int, string)), p string, edits int) map[hit]bool {
+		hits := map[hit]bool{}
+		search(p, edits, func(i int, cigar string) { hits[hit{i, cigar}] = true })
+
+		return hits
+	}
+
+	rng := testutils.NewRandomSeed(t)
+	for i := 0; i < 20; i++ {
+		x := testutils.RandomStringRange(10, 50, "acgt", rng)
+		p := testutils.PickRandomSubstring(x, rng)
+
+		full := gostr.FMIndexApproxFromTables(gostr.BuildFMIndexApproxTables(x))
+		sampled := gostr.FMIndexApproxFromTables(
+			gostr.BuildFMIndexApproxTables(x, gostr.WithSASampling(4)))
+
+		for edits := 0; edits < 3; edits++ {
+			expected := collect(full, p, edits)
+			if hits := collect(sampled, p, edits); !reflect.DeepEqual(expected, hits) {
+				t.Fatalf("Sampled and full suffix arrays disagree for %q in %q with %d edits",
+					p, x, edits)
+			}
+		}
+	}
+}
+
+func TestSampledOTab(t *testing.T) {
+	rng := testutils.NewRandomSeed(t)
+	testutils.GenerateTestStrings(50, 300, rng, func(x string) {
+		xb, alpha := gostr.MapStringWithSentinel(x)
+		sa, _ := gostr.SaisWithAlphabet(x, alpha)
+		bwt := gostr.Bwt(xb, sa)
+		otab := gostr.NewOTab(bwt, alpha.Size())
+
+		for _, k := range []int{1, 64, 100, 256} {
+			sotab := gostr.NewSampledOTab(bwt, alpha.Size(), k)
+
+			for a := 1; a < alpha.Size(); a++ {
+				for i := 0; i <= len(bwt); i++ {
+					if otab.Rank(byte(a), i) != sotab.Rank(byte(a), i) {
+						t.Fatalf("Rank(%d,%d) differs for k = %d: %d vs %d",
+							a, i, k, otab.Rank(byte(a), i), sotab.Rank(byte(a), i))
+					}
+				}
+			}
+
+			for i, a := range bwt {
+				if sotab.Access(i) != a {
+					t.Fatalf("Access(%d) = %d for k = %d, expected %d", i, sotab.Access(i), k, a)
+				}
+			}
+
+			if y := gostr.ReverseBwtWithOTab(bwt, sotab); !reflect.DeepEqual(xb, y) {
+				t.Fatalf("Expected %s == %s",
+					alpha.RevmapBytes(xb), alpha.RevmapBytes(y))
+			}
+		}
+	})
+}
+
+func TestSampledOTabSearch(t *testing.T) {
+	rng := testutils.NewRandomSeed(t)
+	testutils.GenerateTestStringsAndPatterns(10, 50, rng,
+		func(x, p string) {
+			full := gostr.FMIndexExactFromTables(gostr.BuildFMIndexExactTables(x))
+			sampled := gostr.FMIndexExactFromTables(
+				gostr.BuildFMIndexExactTables(x,
+					gostr.WithOTabSampling(64), gostr.WithSASampling(3)))
+
+			expected := collectExactHits(full, p)
+			if hits := collectExactHits(sampled, p); !reflect.DeepEqual(expected, hits) {
+				t.Fatalf("Got hits %v for %q in %q, expected %v", hits, p, x, expected)
+			}
+		})
+}
@@ -0,0 +1,195 @@
+package gostr
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// CigarOp is a run of Len copies of the same edit operation.
+type CigarOp struct {
+	Op  ApproxEdit
+	Len int
+}
+
+// Cigar is the run-length encoding of a list of edit operations, the
+// way a cigar string writes it, but without spelling out each
+// operation the way EditOps does.
+type Cigar []CigarOp
+
+// opCodes maps a byte to the operation it stands for in a cigar, and
+// to one more than the number of operations if it isn't one.
+var opCodes = func() (codes [256]ApproxEdit) { //nolint:gochecknoglobals // a constant table
+	for a := range codes {
+		codes[a] = Pad + 1
+	}
+
+	for s, op := range stringToEdits {
+		codes[s[0]] = op
+	}
+
+	return codes
+}()
+
+// ParseCigar parses a cigar string in a single scan. If the cigar is
+// invalid, the error tells you the offset of the first byte where we
+// could see it, which is the end of the string if it ends in the
+// middle of an operation.
+func ParseCigar(cigar string) (Cigar, error) {
+	res := Cigar{}
+
+	for i := 0; i < len(cigar); {
+		// Chomp off the digits
+		n, start := 0, i
+
+		for ; i < len(cigar) && '0' <= cigar[i] && cigar[i] <= '9'; i++ {
+			d := int(cigar[i] - '0')
+			if n > (math.MaxInt32-d)/10 { //nolint:gomnd // base ten
+				return nil, NewInvalidCigar(cigar, i) // unreasonably long run
+			}
+
+			n = 10*n + d //nolint:gomnd // base ten
+		}
+
+		switch {
+		case i == len(cigar):
+			return nil, NewInvalidCigar(cigar, i) // digits without an operation
+		case i == start, opCodes[cigar[i]] > Pad:
+			return nil, NewInvalidCigar(cigar, i) // not a digit or an operation
+		}
+
+		res = append(res, CigarOp{Op: opCodes[cigar[i]], Len: n})
+		i++
+	}
+
+	return res, nil
+}
+
+// CigarFromOps run-length encodes a list of edit operations.
+func CigarFromOps(ops EditOps) Cigar {
+	res := Cigar{}
+
+	for _, op := range ops {
+		if k := len(res) - 1; k >= 0 && res[k].Op == op {
+			res[k].Len++
+		} else {
+			res = append(res, CigarOp{Op: op, Len: 1})
+		}
+	}
+
+	return res
+}
+
+// String writes the cigar in the usual string format.
+func (c Cigar) String() string {
+	var res strings.Builder
+
+	for _, run := range c {
+		res.WriteString(strconv.Itoa(run.Len))
+		res.WriteString(editsToString[run.Op])
+	}
+
+	return res.String()
+}
+
+// Ops expands the cigar to one edit operation for each letter.
+func (c Cigar) Ops() EditOps {
+	ops := make(EditOps, 0, c.Len())
+
+	c.Each(func(op ApproxEdit) { ops = append(ops, op) })
+
+	return ops
+}
+
+// Len returns the number of operations in the cigar, i.e., the sum of
+// the run lengths.
+func (c Cigar) Len() int {
+	n := 0
+
+	for _, run := range c {
+		n += run.Len
+	}
+
+	return n
+}
+
+// Each calls fn for each operation in the cigar, in order, as if we
+// had expanded it to EditOps.
+func (c Cigar) Each(fn func(op ApproxEdit)) {
+	for _, run := range c {
+		for i := 0; i < run.Len; i++ {
+			fn(run.Op)
+		}
+	}
+}
+
+// RefLength returns the number of reference letters the cigar covers.
+func (c Cigar) RefLength() int {
+	n := 0
+
+	for _, run := range c {
+		if run.Op.consumesRef() {
+			n += run.Len
+		}
+	}
+
+	return n
+}
+
+// ReadLength returns the number of read letters the cigar covers,
+// including soft clipped letters but not hard clipped.
+func (c Cigar) ReadLength() int {
+	n := 0
+
+	for _, run := range c {
+		if run.Op.consumesRead() {
+			n += run.Len
+		}
+	}
+
+	return n
+}
+
+// Reverse returns the cigar for the operations in the opposite order.
+func (c Cigar) Reverse() Cigar {
+	rev := make(Cigar, len(c))
+
+	for i, run := range c {
+		rev[len(c)-1-i] = run
+	}
+
+	return rev
+}
+
+// Merge returns the cigar where adjacent runs of the same operation
+// are joined into one, and empty runs are gone, so each operation is
+// written the way CigarFromOps would write it.
+func (c Cigar) Merge() Cigar {
+	res := Cigar{}
+
+	for _, run := range c {
+		switch k := len(res) - 1; {
+		case run.Len == 0:
+			continue
+		case k >= 0 && res[k].Op == run.Op:
+			res[k].Len += run.Len
+		default:
+			res = append(res, run)
+		}
+	}
+
+	return res
+}
+
+// Concat returns the cigar for the operations in c followed by the
+// operations in the others, merged as Merge does, so runs of the same
+// operation where they meet become one.
+func (c Cigar) Concat(others ...Cigar) Cigar {
+	res := append(Cigar{}, c...)
+
+	for _, other := range others {
+		res = append(res, other...)
+	}
+
+	return res.Merge()
+}
@@ -0,0 +1,182 @@
+package gostr_test
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/mailund/gostr/gostr"
+	"github.com/mailund/gostr/testutils"
+)
+
+func TestParseCigar(t *testing.T) {
+	tests := []struct {
+		cigar string
+		want  gostr.Cigar
+	}{
+		{"", gostr.Cigar{}},
+		{"1M", gostr.Cigar{{gostr.Match, 1}}},
+		{"12M3I", gostr.Cigar{{gostr.Match, 12}, {gostr.Insert, 3}}},
+		{"2S3=1X4N1P1D2H", gostr.Cigar{
+			{gostr.SoftClip, 2}, {gostr.SeqMatch, 3}, {gostr.Mismatch, 1}, {gostr.Skip, 4},
+			{gostr.Pad, 1}, {gostr.Delete, 1}, {gostr.HardClip, 2},
+		}},
+		{"0M", gostr.Cigar{{gostr.Match, 0}}},
+	}
+
+	for _, tt := range tests {
+		got, err := gostr.ParseCigar(tt.cigar)
+		if err != nil {
+			t.Fatalf("Unexpected error parsing %q: %v", tt.cigar, err)
+		}
+
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseCigar(%q) = %v, want %v", tt.cigar, got, tt.want)
+		}
+	}
+}
+
+func TestParseCigarErrors(t *testing.T) {
+	tests := []struct {
+		cigar  string
+		offset int
+	}{
+		{"M", 0},
+		{"3", 1},
+		{"3M4", 3},
+		{"3M4Q", 3},
+		{"3M 4I", 2},
+		{"3m", 1},
+		{"-3M", 0},
+		{"1M99999999999I", 11},
+	}
+
+	for _, tt := range tests {
+		_, err := gostr.ParseCigar(tt.cigar)
+
+		var ic *gostr.InvalidCigar
+		if !errors.As(err, &ic) {
+			t.Fatalf("Expected an invalid cigar error for %q, got %v", tt.cigar, err)
+		}
+
+		if ic.Offset() != tt.offset {
+			t.Errorf("Error for %q at offset %d, expected %d", tt.cigar, ic.Offset(), tt.offset)
+		}
+
+		if !errors.Is(err, gostr.NewInvalidCigar(tt.cigar, tt.offset)) {
+			t.Errorf("Unexpected error for %q: %v", tt.cigar, err)
+		}
+	}
+}
+
+func randomOps(n int, rng *rand.Rand) gostr.EditOps {
+	ops := make(gostr.EditOps, n)
+	for i := range ops {
+		ops[i] = gostr.ApproxEdit(rng.Intn(int(gostr.Pad) + 1))
+	}
+
+	return ops
+}
+
+func TestCigarRoundTrip(t *testing.T) {
+	rng := testutils.NewRandomSeed(t)
+
+	for i := 0; i < 100; i++ {
+		ops := randomOps(rng.Intn(30), rng)
+		c := gostr.CigarFromOps(ops)
+
+		if !reflect.DeepEqual(c.Ops(), ops) {
+			t.Fatalf("%v expands to %v, expected %v", c, c.Ops(), ops)
+		}
+
+		if c.Len() != len(ops) {
+			t.Errorf("%v has length %d, expected %d", c, c.Len(), len(ops))
+		}
+
+		parsed, err := gostr.ParseCigar(c.String())
+		if err != nil || !reflect.DeepEqual(parsed, c) {
+			t.Errorf("%q parses as %v (%v), expected %v", c.String(), parsed, err, c)
+		}
+
+		if c.String() != gostr.OpsToCigar(ops) {
+			t.Errorf("%q differs from OpsToCigar, %q", c.String(), gostr.OpsToCigar(ops))
+		}
+
+		each := gostr.EditOps{}
+		c.Each(func(op gostr.ApproxEdit) { each = append(each, op) })
+
+		if !reflect.DeepEqual(each, ops) {
+			t.Errorf("Each gives %v, expected %v", each, ops)
+		}
+
+		rev := gostr.EditOps{}
+		for j := len(ops) - 1; j >= 0; j-- {
+			rev = append(rev, ops[j])
+		}
+
+		if !reflect.DeepEqual(c.Reverse(), gostr.CigarFromOps(rev)) {
+			t.Errorf("%v reversed is %v, expected %v", c, c.Reverse(), gostr.CigarFromOps(rev))
+		}
+
+		// Splitting anywhere and concatenating gives us the cigar back
+		k := rng.Intn(len(ops) + 1)
+		left, right := gostr.CigarFromOps(ops[:k]), gostr.CigarFromOps(ops[k:])
+
+		if cat := left.Concat(right); !reflect.DeepEqual(cat, c) {
+			t.Errorf("%v + %v = %v, expected %v", left, right, cat, c)
+		}
+	}
+}
+
+func TestCigarMerge(t *testing.T) {
+	c := gostr.Cigar{{gostr.Match, 2}, {gostr.Match, 0}, {gostr.Insert, 0}, {gostr.Match, 3}, {gostr.Delete, 1}}
+	want := gostr.Cigar{{gostr.Match, 5}, {gostr.Delete, 1}}
+
+	if got := c.Merge(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Merge() = %v, want %v", got, want)
+	}
+
+	if got := (gostr.Cigar{}).Concat(gostr.Cigar{}, want); !reflect.DeepEqual(got, want) {
+		t.Errorf("Concat() = %v, want %v", got, want)
+	}
+}
+
+func TestCigarLengths(t *testing.T) {
+	c, _ := gostr.ParseCigar("1H2S3M1I2D4N2=1X1P")
+
+	if n := c.RefLength(); n != 3+2+4+2+1 {
+		t.Errorf("Unexpected reference length %d", n)
+	}
+
+	if n := c.ReadLength(); n != 2+3+1+2+1 {
+		t.Errorf("Unexpected read length %d", n)
+	}
+}
+
+func TestCigarCountEdits(t *testing.T) {
+	rng := testutils.NewRandomSeed(t)
+
+	for i := 0; i < 100; i++ {
+		x := testutils.RandomStringRange(20, 40, "acg", rng)
+		p := testutils.RandomStringRange(5, 10, "acg", rng)
+		search := gostr.FMIndexApproxPreprocess(x)
+
+		search(p, 2, func(pos int, cigar string) {
+			c, _ := gostr.ParseCigar(cigar)
+			subx, subp := c.Extract(x, p, pos)
+
+			edits := 0
+
+			for j := range subx {
+				if subx[j] != subp[j] {
+					edits++
+				}
+			}
+
+			if n := c.CountEdits(x, p, pos); n != edits {
+				t.Errorf("CountEdits for %s at %d is %d, expected %d", cigar, pos, n, edits)
+			}
+		})
+	}
+}
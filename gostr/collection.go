@@ -0,0 +1,172 @@
+package gostr
+
+import (
+	"sort"
+	"strings"
+)
+
+// SeqRecord is a named sequence, e.g., a chromosome or a contig
+// from a FASTA file.
+type SeqRecord struct {
+	Name string
+	Seq  string
+}
+
+// SeqCollection is a set of records concatenated into one string, so
+// we can index them all with the single-string index builders. The
+// records are separated by a byte, Sep, that doesn't occur in any of
+// them, so no occurrence of a pattern without Sep can span two records.
+// Starts[i] is the position in Seq where record Names[i] begins.
+type SeqCollection struct {
+	Names  []string
+	Starts []int
+	Seq    string
+	Sep    byte
+}
+
+// findSeparator returns a byte that doesn't occur in any of the records.
+// We never use zero, since that is the sentinel in the indices.
+func findSeparator(records []SeqRecord) (byte, bool) {
+	var used [256]bool
+
+	for _, rec := range records {
+		for i := 0; i < len(rec.Seq); i++ {
+			used[rec.Seq[i]] = true
+		}
+	}
+
+	for a := 1; a < len(used); a++ {
+		if !used[a] {
+			return byte(a), true
+		}
+	}
+
+	return 0, false
+}
+
+// NewSeqCollection concatenates records into a collection. It fails
+// if the records use all the bytes, so there is no byte left that
+// can separate them.
+func NewSeqCollection(records []SeqRecord) (*SeqCollection, error) {
+	sep, ok := findSeparator(records)
+	if !ok {
+		return nil, NewNoSeparator()
+	}
+
+	var (
+		names  = make([]string, len(records))
+		starts = make([]int, len(records))
+		seq    strings.Builder
+	)
+
+	for i, rec := range records {
+		if i > 0 {
+			seq.WriteByte(sep)
+		}
+
+		names[i], starts[i] = rec.Name, seq.Len()
+		seq.WriteString(rec.Seq)
+	}
+
+	return &SeqCollection{Names: names, Starts: starts, Seq: seq.String(), Sep: sep}, nil
+}
+
+// recordEnd returns the position one past the last letter in record i.
+func (c *SeqCollection) recordEnd(i int) int {
+	if i+1 < len(c.Starts) {
+		return c.Starts[i+1] - 1 // minus the separator
+	}
+
+	return len(c.Seq)
+}
+
+// Locate maps an occurrence of length letters, starting at position pos
+// in Seq, to the record it is in and the offset into that record. If
+// the occurrence doesn't fit inside a single record, it returns false.
+func (c *SeqCollection) Locate(pos, length int) (name string, offset int, ok bool) {
+	// The last record that starts at or before pos
+	i := sort.Search(len(c.Starts), func(j int) bool { return c.Starts[j] > pos }) - 1
+	if i < 0 || pos+length > c.recordEnd(i) {
+		return "", 0, false
+	}
+
+	return c.Names[i], pos - c.Starts[i], true
+}
+
+// cigarRefLength returns the number of reference letters a cigar
+// covers. The searches only report valid cigars, but rather than parse
+// the cigar, and panic in the search if it isn't, we add up the runs of
+// the operations that cover the reference and skip anything else.
+func cigarRefLength(cigar string) int {
+	n, run := 0, 0
+
+	for i := 0; i < len(cigar); i++ {
+		if a := cigar[i]; '0' <= a && a <= '9' {
+			run = 10*run + int(a-'0') //nolint:gomnd // base ten
+			continue
+		}
+
+		if opCodes[cigar[i]].consumesRef() {
+			n += run
+		}
+
+		run = 0
+	}
+
+	return n
+}
+
+// FMIndexExactFromTables returns a search function based on tables
+// built from the collection's Seq. It reports the record and offset
+// of each occurrence.
+func (c *SeqCollection) FMIndexExactFromTables(tbls *FMIndexTables) func(p string, cb func(name string, offset int)) {
+	search := FMIndexExactFromTables(tbls)
+
+	return func(p string, cb func(name string, offset int)) {
+		search(p, func(i int) {
+			if name, offset, ok := c.Locate(i, len(p)); ok {
+				cb(name, offset)
+			}
+		})
+	}
+}
+
+// FMIndexExactPreprocess builds the tables for the collection and
+// returns a function you can use to search in it.
+func (c *SeqCollection) FMIndexExactPreprocess(opts ...FMIndexOption) func(p string, cb func(name string, offset int)) {
+	return c.FMIndexExactFromTables(BuildFMIndexExactTables(c.Seq, opts...))
+}
+
+// FMIndexApproxFromTables returns an approximative search function
+// based on tables built from the collection's Seq. It reports the
+// record and offset of each occurrence together with its cigar.
+func (c *SeqCollection) FMIndexApproxFromTables(tbls *FMIndexTables) func(p string, edits int, cb func(name string, offset int, cigar string)) {
+	search := FMIndexApproxFromTables(tbls)
+
+	return func(p string, edits int, cb func(name string, offset int, cigar string)) {
+		search(p, edits, func(i int, cigar string) {
+			if name, offset, ok := c.Locate(i, cigarRefLength(cigar)); ok {
+				cb(name, offset, cigar)
+			}
+		})
+	}
+}
+
+// FMIndexApproxPreprocess builds the tables for the collection and
+// returns a function you can use for approximative search in it.
+func (c *SeqCollection) FMIndexApproxPreprocess(opts ...FMIndexOption) func(p string, edits int, cb func(name string, offset int, cigar string)) {
+	return c.FMIndexApproxFromTables(BuildFMIndexApproxTables(c.Seq, opts...))
+}
+
+// STSearch returns a search function that uses st, a suffix tree
+// built from the collection's Seq, and reports the record and offset
+// of each occurrence.
+func (c *SeqCollection) STSearch(st *SuffixTree) func(p string, cb func(name string, offset int)) {
+	return func(p string, cb func(name string, offset int)) {
+		st.Search(p, func(i int) {
+			if name, offset, ok := c.Locate(i, len(p)); ok {
+				cb(name, offset)
+			}
+		})
+	}
+}
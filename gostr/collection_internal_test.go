@@ -0,0 +1,21 @@
+package gostr
+
+import "testing"
+
+func TestCigarRefLength(t *testing.T) {
+	for _, cigar := range []string{"", "4M", "2M1I2M", "2M1D1M", "2S2=1X2N3M1P1I4H", "12M10D"} {
+		c, err := ParseCigar(cigar)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		if n := cigarRefLength(cigar); n != c.RefLength() {
+			t.Errorf("Cigar %s covers %d reference letters, not %d", cigar, c.RefLength(), n)
+		}
+	}
+
+	// Invalid cigars don't make us panic
+	for _, cigar := range []string{"invalid", "4", "M", "4Q2M", "99999999999999999999M"} {
+		cigarRefLength(cigar)
+	}
+}
@@ -0,0 +1,176 @@
+package gostr_test
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/mailund/gostr/gostr"
+	"github.com/mailund/gostr/testutils"
+)
+
+func randomRecords(n int, alpha string, rng *rand.Rand) []gostr.SeqRecord {
+	records := make([]gostr.SeqRecord, n)
+	for i := range records {
+		records[i] = gostr.SeqRecord{
+			Name: fmt.Sprintf("chr%d", i+1),
+			Seq:  testutils.RandomStringRange(0, 50, alpha, rng),
+		}
+	}
+
+	return records
+}
+
+// collectionHits runs the exact search in each record on its own,
+// so we know which hits the collection should give us.
+func collectionHits(records []gostr.SeqRecord, p string) []string {
+	hits := []string{}
+
+	for _, rec := range records {
+		gostr.Naive(rec.Seq, p, func(i int) {
+			hits = append(hits, fmt.Sprintf("%s:%d", rec.Name, i))
+		})
+	}
+
+	sort.Strings(hits)
+
+	return hits
+}
+
+func TestSeqCollectionLocate(t *testing.T) {
+	c, err := gostr.NewSeqCollection([]gostr.SeqRecord{
+		{Name: "a", Seq: "acgt"},
+		{Name: "b", Seq: ""},
+		{Name: "c", Seq: "tt"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if c.Seq != "acgt\x01\x01tt" {
+		t.Fatalf("Unexpected concatenation: %q", c.Seq)
+	}
+
+	tests := []struct {
+		pos, length int
+		name        string
+		offset      int
+		ok          bool
+	}{
+		{0, 4, "a", 0, true},
+		{2, 2, "a", 2, true},
+		{2, 3, "", 0, false},
+		{4, 0, "a", 4, true},
+		{5, 0, "b", 0, true},
+		{5, 1, "", 0, false},
+		{6, 2, "c", 0, true},
+		{7, 2, "", 0, false},
+	}
+
+	for _, tt := range tests {
+		name, offset, ok := c.Locate(tt.pos, tt.length)
+		if name != tt.name || offset != tt.offset || ok != tt.ok {
+			t.Errorf("Locate(%d, %d) = (%q, %d, %t), expected (%q, %d, %t)",
+				tt.pos, tt.length, name, offset, ok, tt.name, tt.offset, tt.ok)
+		}
+	}
+}
+
+func TestSeqCollectionNoSeparator(t *testing.T) {
+	all := make([]byte, 255)
+	for i := range all {
+		all[i] = byte(i + 1)
+	}
+
+	_, err := gostr.NewSeqCollection([]gostr.SeqRecord{{Name: "all", Seq: string(all)}})
+	if !errors.Is(err, gostr.NewNoSeparator()) {
+		t.Errorf("Expected a NoSeparator error, got %q", err)
+	}
+}
+
+func TestSeqCollectionExact(t *testing.T) {
+	rng := testutils.NewRandomSeed(t)
+
+	for n := 0; n < 20; n++ {
+		records := randomRecords(1+rng.Intn(5), "acg", rng)
+
+		c, err := gostr.NewSeqCollection(records)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		searches := map[string]func(string, func(string, int)){
+			"FM": c.FMIndexExactPreprocess(),
+			"ST": c.STSearch(gostr.McCreight(c.Seq)),
+		}
+
+		for j := 0; j < 10; j++ {
+			p := testutils.RandomStringRange(1, 5, "acg", rng)
+			expected := collectionHits(records, p)
+
+			for name, search := range searches {
+				hits := []string{}
+
+				search(p, func(name string, offset int) {
+					hits = append(hits, fmt.Sprintf("%s:%d", name, offset))
+				})
+				sort.Strings(hits)
+
+				if !reflect.DeepEqual(hits, expected) {
+					t.Fatalf("%s search for %q in %v gave %v, expected %v", name, p, records, hits, expected)
+				}
+			}
+		}
+	}
+}
+
+func TestSeqCollectionApprox(t *testing.T) {
+	rng := testutils.NewRandomSeed(t)
+
+	for n := 0; n < 20; n++ {
+		records := randomRecords(1+rng.Intn(5), "acg", rng)
+
+		// The single-record indices can only match letters in their
+		// own alphabet, so we make sure all records have all letters.
+		for i := range records {
+			records[i].Seq += "acg"
+		}
+
+		c, err := gostr.NewSeqCollection(records)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		search := c.FMIndexApproxPreprocess()
+
+		for j := 0; j < 10; j++ {
+			p := testutils.RandomStringRange(1, 5, "acg", rng)
+			edits := rng.Intn(3)
+
+			// Searching in each record on its own should give us the same hits
+			expected := []string{}
+
+			for _, rec := range records {
+				gostr.FMIndexApproxPreprocess(rec.Seq)(p, edits, func(i int, cigar string) {
+					expected = append(expected, fmt.Sprintf("%s:%d:%s", rec.Name, i, cigar))
+				})
+			}
+
+			hits := []string{}
+
+			search(p, edits, func(name string, offset int, cigar string) {
+				hits = append(hits, fmt.Sprintf("%s:%d:%s", name, offset, cigar))
+			})
+
+			sort.Strings(expected)
+			sort.Strings(hits)
+
+			if !reflect.DeepEqual(hits, expected) {
+				t.Fatalf("Search for %q with %d edits in %v gave %v, expected %v", p, edits, records, hits, expected)
+			}
+		}
+	}
+}
@@ -13,25 +13,152 @@ func (err *AlphabetLookupError) Error() string {
 	return fmt.Sprintf("byte %c is not in alphabet", err.char)
 }
 
-// InvalidCigar are errors when you use a cigar that isn't in the right format
+// InvalidCigar are errors when you use a cigar that isn't in the right format.
+// The offset is the position of the first byte in x where the cigar is wrong.
 type InvalidCigar struct {
-	x string
+	x      string
+	offset int
 }
 
 // NewInvalidCigar creates an InvalidCigar error
-func NewInvalidCigar(x string) *InvalidCigar {
-	return &InvalidCigar{x: x}
+func NewInvalidCigar(x string, offset int) *InvalidCigar {
+	return &InvalidCigar{x: x, offset: offset}
+}
+
+// Offset returns the offset of the first invalid byte in the cigar.
+func (err *InvalidCigar) Offset() int {
+	return err.offset
 }
 
 // Error implements the interface for errors.
 func (err *InvalidCigar) Error() string {
-	return fmt.Sprintf("invalid cigar: %s", err.x)
+	return fmt.Sprintf("invalid cigar: %s, at offset %d", err.x, err.offset)
 }
 
 // Is implements the Is interface for errors.
 func (err *InvalidCigar) Is(other error) bool {
 	if ic, ok := other.(*InvalidCigar); ok {
-		return ic.x == err.x
+		return ic.x == err.x && ic.offset == err.offset
+	}
+
+	return false
+}
+
+// InvalidIndexMagic is the error when we read an index that doesn't start
+// with the magic header for the format.
+type InvalidIndexMagic struct {
+	magic string
+}
+
+// NewInvalidIndexMagic creates an InvalidIndexMagic error
+func NewInvalidIndexMagic(magic string) *InvalidIndexMagic {
+	return &InvalidIndexMagic{magic: magic}
+}
+
+// Error implements the interface for errors.
+func (err *InvalidIndexMagic) Error() string {
+	return fmt.Sprintf("invalid index header: %q", err.magic)
+}
+
+// Is implements the Is interface for errors.
+func (err *InvalidIndexMagic) Is(other error) bool {
+	if e, ok := other.(*InvalidIndexMagic); ok {
+		return e.magic == err.magic
+	}
+
+	return false
+}
+
+// UnsupportedIndexVersion is the error when we read an index in a version
+// of the format we do not know how to read.
+type UnsupportedIndexVersion struct {
+	version uint32
+}
+
+// NewUnsupportedIndexVersion creates an UnsupportedIndexVersion error
+func NewUnsupportedIndexVersion(version uint32) *UnsupportedIndexVersion {
+	return &UnsupportedIndexVersion{version: version}
+}
+
+// Error implements the interface for errors.
+func (err *UnsupportedIndexVersion) Error() string {
+	return fmt.Sprintf("unsupported index format version: %d", err.version)
+}
+
+// Is implements the Is interface for errors.
+func (err *UnsupportedIndexVersion) Is(other error) bool {
+	if e, ok := other.(*UnsupportedIndexVersion); ok {
+		return e.version == err.version
+	}
+
+	return false
+}
+
+// IndexChecksumMismatch is the error when a section in an index doesn't
+// match its checksum.
+type IndexChecksumMismatch struct {
+	section uint32
+}
+
+// NewIndexChecksumMismatch creates an IndexChecksumMismatch error
+func NewIndexChecksumMismatch(section uint32) *IndexChecksumMismatch {
+	return &IndexChecksumMismatch{section: section}
+}
+
+// Error implements the interface for errors.
+func (err *IndexChecksumMismatch) Error() string {
+	return fmt.Sprintf("checksum mismatch in index section %d", err.section)
+}
+
+// Is implements the Is interface for errors.
+func (err *IndexChecksumMismatch) Is(other error) bool {
+	if e, ok := other.(*IndexChecksumMismatch); ok {
+		return e.section == err.section
+	}
+
+	return false
+}
+
+// TruncatedIndex is the error when an index ends before we have read
+// all of it.
+type TruncatedIndex struct{}
+
+// NewTruncatedIndex creates a TruncatedIndex error
+func NewTruncatedIndex() *TruncatedIndex {
+	return &TruncatedIndex{}
+}
+
+// Error implements the interface for errors.
+func (err *TruncatedIndex) Error() string {
+	return "truncated index"
+}
+
+// Is implements the Is interface for errors.
+func (err *TruncatedIndex) Is(other error) bool {
+	_, ok := other.(*TruncatedIndex)
+	return ok
+}
+
+// CorruptIndex is the error when an index has the right checksums
+// but its content doesn't make sense.
+type CorruptIndex struct {
+	reason string
+}
+
+// NewCorruptIndex creates a CorruptIndex error
+func NewCorruptIndex(reason string) *CorruptIndex {
+	return &CorruptIndex{reason: reason}
+}
+
+// Error implements the interface for errors.
+func (err *CorruptIndex) Error() string {
+	return fmt.Sprintf("corrupt index: %s", err.reason)
+}
+
+// Is implements the Is interface for errors.
+func (err *CorruptIndex) Is(other error) bool {
+	if e, ok := other.(*CorruptIndex); ok {
+		return e.reason == err.reason
 	}
 
 	return false
@@ -54,3 +181,65 @@ func catchError(err *error) { //nolint:gocritic // no, err should definitely be
 		*err = received
 	}
 }
+
+// NoSeparator is the error when we cannot concatenate a collection of
+// sequences because they use every byte, so none is left to separate them.
+type NoSeparator struct{}
+
+// NewNoSeparator creates a NoSeparator error
+func NewNoSeparator() *NoSeparator {
+	return &NoSeparator{}
+}
+
+// Error implements the interface for errors.
+func (err *NoSeparator) Error() string {
+	return "no unused byte to separate the sequences"
+}
+
+// Is implements the Is interface for errors.
+func (err *NoSeparator) Is(other error) bool {
+	_, ok := other.(*NoSeparator)
+	return ok
+}
+
+// NotDNA is the error when we need the reverse complement of a string,
+// or a text, with a letter that isn't a nucleotide or an IUPAC code.
+type NotDNA struct {
+	char byte
+}
+
+// NewNotDNA creates a NotDNA error
+func NewNotDNA(char byte) *NotDNA {
+	return &NotDNA{char: char}
+}
+
+// Error implements the interface for errors.
+func (err *NotDNA) Error() string {
+	return fmt.Sprintf("byte %q is not a nucleotide or IUPAC code", err.char)
+}
+
+// Is implements the Is interface for errors.
+func (err *NotDNA) Is(other error) bool {
+	_, ok := other.(*NotDNA)
+	return ok
+}
+
+// FinishedSuffixTree is the error when we add letters to an online
+// suffix tree after we have finished it with the sentinel.
+type FinishedSuffixTree struct{}
+
+// NewFinishedSuffixTree creates a FinishedSuffixTree error
+func NewFinishedSuffixTree() *FinishedSuffixTree {
+	return &FinishedSuffixTree{}
+}
+
+// Error implements the interface for errors.
+func (err *FinishedSuffixTree) Error() string {
+	return "cannot append to a finished suffix tree"
+}
+
+// Is implements the Is interface for errors.
+func (err *FinishedSuffixTree) Is(other error) bool {
+	_, ok := other.(*FinishedSuffixTree)
+	return ok
+}
@@ -9,7 +9,7 @@ func checkThenError() (err error) {
 
 	// This should catch the error and make it the return value for
 	// the function.
-	checkError(NewInvalidCigar("foo"))
+	checkError(NewInvalidCigar("foo", 0))
 
 	return nil
 }
@@ -17,7 +17,7 @@ func checkThenError() (err error) {
 func TestCheckCatch(t *testing.T) {
 	if err := checkThenError(); err == nil {
 		t.Fatal("We expected an error")
-	} else if err.Error() != "invalid cigar: foo" {
+	} else if err.Error() != "invalid cigar: foo, at offset 0" {
 		t.Errorf("Unexpected error message: %s", err)
 	}
 }
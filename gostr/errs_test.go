@@ -21,13 +21,13 @@ func TestAlphabetLookupError(t *testing.T) {
 }
 
 func TestInvalidCigar_Is(t *testing.T) {
-	cigarErr := gostr.NewInvalidCigar("foo")
-	if cigarErr.Error() != "invalid cigar: foo" {
+	cigarErr := gostr.NewInvalidCigar("foo", 0)
+	if cigarErr.Error() != "invalid cigar: foo, at offset 0" {
 		t.Errorf("Unexpected error message: %s", cigarErr)
 	}
 
-	otherCigarErr := gostr.NewInvalidCigar("foo")
-	otherDifferentCigarErr := gostr.NewInvalidCigar("bar")
+	otherCigarErr := gostr.NewInvalidCigar("foo", 0)
+	otherDifferentCigarErr := gostr.NewInvalidCigar("bar", 0)
 
 	if !errors.Is(cigarErr, otherCigarErr) {
 		t.Error("these errors should be considered the same")
@@ -37,6 +37,14 @@ func TestInvalidCigar_Is(t *testing.T) {
 		t.Error("these errors should be considered different")
 	}
 
+	if errors.Is(cigarErr, gostr.NewInvalidCigar("foo", 1)) {
+		t.Error("errors at different offsets should be considered different")
+	}
+
+	if cigarErr.Offset() != 0 {
+		t.Errorf("Unexpected offset: %d", cigarErr.Offset())
+	}
+
 	otherErr := errors.New("some other error") //nolint:goerr113 // ignore new error for testing
 	if errors.Is(cigarErr, otherErr) {
 		t.Error("these errors should be considered different")
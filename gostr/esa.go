@@ -0,0 +1,297 @@
+package gostr
+
+// Enhanced suffix arrays (Abouelhoda, Kurtz and Ohlebusch, Replacing
+// suffix trees with enhanced suffix arrays, 2004). The inner nodes of
+// a suffix tree are the lcp-intervals of the suffix array: the ranges
+// [Left, Right] of suffixes that share a prefix of length Lcp, where
+// the suffixes just outside the range share less. The leaves are the
+// singleton intervals. With a child table, we can find the children of
+// an interval in constant time each, so we can traverse the intervals
+// the way we traverse the tree, but all we store is a few integer
+// arrays, where the tree needs a node with a full child array for each
+// inner node.
+
+// ChildTable holds the up, down and next-l-index values that we need
+// to find the children of lcp-intervals. An entry is -1 where the
+// value isn't defined. All three arrays have one more entry than the
+// suffix array, so we can look up the entry after the last interval.
+type ChildTable struct {
+	Up, Down, Next []int32
+}
+
+// EnhancedSuffixArray is a suffix array together with its LCP array
+// and child table. As for SuffixTree, String is the string mapped
+// through Alpha, with the sentinel.
+type EnhancedSuffixArray struct {
+	Alpha  *Alphabet
+	String []byte
+	Sa     []int32
+	Lcp    []int32
+	Child  ChildTable
+}
+
+// LcpInterval is an interval of the suffix array, from Left to Right,
+// both included, where the suffixes share a prefix of length Lcp. A
+// singleton interval is a leaf, and there Lcp is the length of the
+// suffix.
+type LcpInterval struct {
+	esa              *EnhancedSuffixArray
+	Lcp, Left, Right int
+}
+
+// NewEnhancedSuffixArray builds an enhanced suffix array for x.
+func NewEnhancedSuffixArray(x string) *EnhancedSuffixArray {
+	sa := Sais(x)
+	return EnhancedSuffixArrayFromSA(x, sa, LcpKasai(x, sa))
+}
+
+// EnhancedSuffixArrayFromSA builds an enhanced suffix array for x from
+// its suffix array and LCP array. The arrays must include the sentinel
+// suffix, as those from Sais and LcpKasai do.
+func EnhancedSuffixArrayFromSA(x string, sa, lcp []int32) *EnhancedSuffixArray {
+	xb, alpha := MapStringWithSentinel(x)
+	esa := &EnhancedSuffixArray{Alpha: alpha, String: xb, Sa: sa, Lcp: lcp}
+	esa.buildChildTable()
+
+	return esa
+}
+
+// lcpAt is the LCP array with -1 at both ends, so all intervals end
+// before index n.
+func (esa *EnhancedSuffixArray) lcpAt(i int) int32 {
+	if i == 0 || i == len(esa.Sa) {
+		return -1
+	}
+
+	return esa.Lcp[i]
+}
+
+func undefinedTable(n int) []int32 {
+	tbl := make([]int32, n)
+	for i := range tbl {
+		tbl[i] = -1
+	}
+
+	return tbl
+}
+
+// buildChildTable computes the child table with the two stack-based
+// scans from the paper.
+func (esa *EnhancedSuffixArray) buildChildTable() {
+	n := len(esa.Sa)
+	up, down, next := undefinedTable(n+1), undefinedTable(n+1), undefinedTable(n+1)
+
+	// Up and down values
+	stack, last := []int32{0}, int32(-1)
+
+	for i := 1; i <= n; i++ {
+		for esa.lcpAt(i) < esa.lcpAt(int(stack[len(stack)-1])) {
+			last, stack = stack[len(stack)-1], stack[:len(stack)-1]
+
+			top := int(stack[len(stack)-1])
+			if esa.lcpAt(i) <= esa.lcpAt(top) && esa.lcpAt(top) != esa.lcpAt(int(last)) {
+				down[top] = last
+			}
+		}
+
+		if last != -1 {
+			up[i], last = last, -1
+		}
+
+		stack = append(stack, int32(i))
+	}
+
+	// Next-l-index values
+	stack = []int32{0}
+
+	for i := 1; i <= n; i++ {
+		for esa.lcpAt(i) < esa.lcpAt(int(stack[len(stack)-1])) {
+			stack = stack[:len(stack)-1]
+		}
+
+		if top := stack[len(stack)-1]; esa.lcpAt(i) == esa.lcpAt(int(top)) {
+			stack = stack[:len(stack)-1]
+			next[top] = int32(i)
+		}
+
+		stack = append(stack, int32(i))
+	}
+
+	esa.Child = ChildTable{Up: up, Down: down, Next: next}
+}
+
+// firstChildEnd returns the index where the second child of the
+// interval [i, j] starts.
+func (esa *EnhancedSuffixArray) firstChildEnd(i, j int) int {
+	if up := int(esa.Child.Up[j+1]); i < up && up <= j {
+		return up
+	}
+
+	return int(esa.Child.Down[i])
+}
+
+// interval returns the lcp-interval from i to j.
+func (esa *EnhancedSuffixArray) interval(i, j int) LcpInterval {
+	if i == j {
+		return LcpInterval{esa: esa, Lcp: len(esa.String) - int(esa.Sa[i]), Left: i, Right: j}
+	}
+
+	return LcpInterval{esa: esa, Lcp: int(esa.Lcp[esa.firstChildEnd(i, j)]), Left: i, Right: j}
+}
+
+// Root returns the interval that covers the whole suffix array, which
+// is the root of the suffix tree.
+func (esa *EnhancedSuffixArray) Root() LcpInterval {
+	root := esa.interval(0, len(esa.Sa)-1)
+	if root.IsLeaf() {
+		root.Lcp = 0 // the tree for the empty string only has the sentinel
+	}
+
+	return root
+}
+
+// IsLeaf tells us if the interval is a leaf.
+func (iv LcpInterval) IsLeaf() bool {
+	return iv.Left == iv.Right
+}
+
+// Children calls fn with the child intervals of iv, in order.
+func (iv LcpInterval) Children(fn func(LcpInterval)) {
+	if iv.IsLeaf() {
+		return
+	}
+
+	esa, i := iv.esa, iv.Left
+	start := esa.firstChildEnd(iv.Left, iv.Right)
+
+	fn(esa.interval(i, start-1))
+
+	for next := esa.Child.Next[start]; next != -1; next = esa.Child.Next[start] {
+		fn(esa.interval(start, int(next)-1))
+		start = int(next)
+	}
+
+	fn(esa.interval(start, iv.Right))
+}
+
+// LeafIndices maps fn over all the leaf indices in the interval, as
+// STNode.LeafIndices does for the subtree.
+func (iv LcpInterval) LeafIndices(fn func(int)) {
+	for i := iv.Left; i <= iv.Right; i++ {
+		fn(int(iv.esa.Sa[i]))
+	}
+}
+
+// PathLabel returns the prefix the suffixes in the interval share, as
+// STNode.PathLabel returns the string from the root down to a node.
+func (iv LcpInterval) PathLabel(alpha *Alphabet) string {
+	start := int(iv.esa.Sa[iv.Left])
+	return alpha.RevmapBytes(iv.esa.String[start : start+iv.Lcp])
+}
+
+// child returns the child of iv whose edge starts with a, if there is
+// one.
+func (iv LcpInterval) child(a byte) (LcpInterval, bool) {
+	var (
+		res   LcpInterval
+		found bool
+	)
+
+	iv.Children(func(child LcpInterval) {
+		if !found && iv.esa.String[int(iv.esa.Sa[child.Left])+iv.Lcp] == a {
+			res, found = child, true
+		}
+	})
+
+	return res, found
+}
+
+// Locate returns the interval of the suffixes that start with p, if
+// there are any.
+func (esa *EnhancedSuffixArray) Locate(p string) (LcpInterval, bool) {
+	pb, err := esa.Alpha.MapToBytes(p)
+	if err != nil {
+		return LcpInterval{}, false // we can't map, so no hits
+	}
+
+	iv := esa.Root()
+
+	for depth := 0; depth < len(pb); {
+		child, ok := iv.child(pb[depth])
+		if !ok {
+			return LcpInterval{}, false
+		}
+
+		// Compare the rest of the edge down to the child.
+		suffix := esa.String[esa.Sa[child.Left]:]
+		end := smallest(child.Lcp, len(pb))
+
+		if string(suffix[depth:end]) != string(pb[depth:end]) {
+			return LcpInterval{}, false
+		}
+
+		iv, depth = child, end
+	}
+
+	return iv, true
+}
+
+// Search maps visitor through all the leaves in the interval found by
+// a search, as SuffixTree.Search does.
+func (esa *EnhancedSuffixArray) Search(p string, visitor func(int)) {
+	if iv, ok := esa.Locate(p); ok {
+		iv.LeafIndices(visitor)
+	}
+}
+
+// Intervals calls fn with all the lcp-intervals that are not leaves,
+// i.e., the inner nodes of the suffix tree, top-down, in the order of
+// a depth-first traversal where we see a node before its children.
+func (esa *EnhancedSuffixArray) Intervals(fn func(LcpInterval)) {
+	var traverse func(iv LcpInterval)
+	traverse = func(iv LcpInterval) {
+		if iv.IsLeaf() {
+			return
+		}
+
+		fn(iv)
+		iv.Children(traverse)
+	}
+
+	traverse(esa.Root())
+}
+
+// BottomUp calls fn with all the lcp-intervals that are not leaves,
+// bottom-up, so we see an interval after all the intervals inside it.
+// It doesn't use the child table, just a scan through the LCP array
+// with a stack of the intervals we are inside.
+func (esa *EnhancedSuffixArray) BottomUp(fn func(LcpInterval)) {
+	type open struct {
+		lcp, left int
+	}
+
+	stack := []open{{lcp: 0, left: 0}}
+
+	// The LCP is -1 after the last suffix, so we close all the
+	// intervals there, including the root.
+	for i := 1; i <= len(esa.Sa); i++ {
+		left, l := i-1, int(esa.lcpAt(i))
+
+		for len(stack) > 0 && l < stack[len(stack)-1].lcp {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+			// Only the root of the tree for the empty string is a
+			// singleton here, and that is a leaf.
+			if top.left < i-1 {
+				fn(LcpInterval{esa: esa, Lcp: top.lcp, Left: top.left, Right: i - 1})
+			}
+
+			left = top.left
+		}
+
+		if len(stack) > 0 && l > stack[len(stack)-1].lcp {
+			stack = append(stack, open{lcp: l, left: left})
+		}
+	}
+}
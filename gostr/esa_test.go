@@ -0,0 +1,161 @@
+package gostr_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mailund/gostr/gostr"
+	"github.com/mailund/gostr/testutils"
+)
+
+type interval struct {
+	lcp, left, right int
+}
+
+// stIntervals collects the lcp-intervals of the inner nodes in a
+// suffix tree, from their depth and the ranks of their first and last
+// leaves.
+func stIntervals(st *gostr.SuffixTree) map[interval]bool {
+	intervals := map[interval]bool{}
+	rank := 0
+
+	var traverse func(n gostr.STNode, depth int) (left, right int)
+	traverse = func(n gostr.STNode, depth int) (left, right int) {
+		if n.NodeType == gostr.Leaf {
+			rank++
+			return rank - 1, rank - 1
+		}
+
+		left = -1
+
+		for _, child := range n.Inner().Children {
+			if !child.IsNil() {
+				l, r := traverse(child, depth+len(child.Shared().EdgeLabel))
+				if left < 0 {
+					left = l
+				}
+
+				right = r
+			}
+		}
+
+		if left < right {
+			intervals[interval{depth, left, right}] = true
+		}
+
+		return left, right
+	}
+
+	traverse(st.Root, 0)
+
+	return intervals
+}
+
+func esaIntervals(traversal func(fn func(gostr.LcpInterval))) map[interval]bool {
+	intervals := map[interval]bool{}
+
+	traversal(func(iv gostr.LcpInterval) {
+		intervals[interval{iv.Lcp, iv.Left, iv.Right}] = true
+	})
+
+	return intervals
+}
+
+func TestEsaMississippi(t *testing.T) {
+	esa := gostr.NewEnhancedSuffixArray("mississippi")
+
+	hits := []int{}
+	esa.Search("ssi", func(i int) { hits = append(hits, i) })
+
+	if !reflect.DeepEqual(hits, []int{5, 2}) {
+		t.Errorf("Expected to find ssi at 5 and 2, found %v", hits)
+	}
+
+	esa.Search("spi", func(i int) { t.Errorf("Found spi at %d", i) })
+	esa.Search("x", func(i int) { t.Errorf("Found x at %d", i) })
+
+	if iv, ok := esa.Locate("ss"); !ok || iv.Lcp != 3 || iv.PathLabel(esa.Alpha) != "ssi" {
+		t.Errorf("Expected ss to end on the edge to ssi, got %v (%t)", iv, ok)
+	}
+
+	st := gostr.McCreight("mississippi")
+	labels, expected := []string{}, []string{}
+
+	esa.Root().Children(func(iv gostr.LcpInterval) {
+		labels = append(labels, iv.PathLabel(esa.Alpha))
+	})
+
+	for _, child := range st.Root.Inner().Children {
+		if !child.IsNil() {
+			expected = append(expected, child.PathLabel(st.Alpha))
+		}
+	}
+
+	if !reflect.DeepEqual(labels, expected) {
+		t.Errorf("Expected the root's children to be %q, got %q", expected, labels)
+	}
+}
+
+func TestEsaEmpty(t *testing.T) {
+	esa := gostr.NewEnhancedSuffixArray("")
+
+	hits := []int{}
+	esa.Search("", func(i int) { hits = append(hits, i) })
+
+	if !reflect.DeepEqual(hits, []int{0}) {
+		t.Errorf("Expected the empty pattern at 0, found %v", hits)
+	}
+
+	esa.Search("a", func(i int) { t.Errorf("Found a at %d", i) })
+	esa.Intervals(func(iv gostr.LcpInterval) { t.Errorf("Unexpected interval %v", iv) })
+	esa.BottomUp(func(iv gostr.LcpInterval) { t.Errorf("Unexpected interval %v", iv) })
+}
+
+// The enhanced suffix array finds the same as the suffix tree, and its
+// intervals are the tree's inner nodes.
+func TestEsaSuffixTree(t *testing.T) {
+	rng := testutils.NewRandomSeed(t)
+
+	testutils.GenerateTestStringsAndPatterns(10, 50, rng, func(x, p string) {
+		st, esa := gostr.McCreight(x), gostr.NewEnhancedSuffixArray(x)
+
+		stHits, esaHits := []int{}, []int{}
+		st.Search(p, func(i int) { stHits = append(stHits, i) })
+		esa.Search(p, func(i int) { esaHits = append(esaHits, i) })
+
+		if !reflect.DeepEqual(stHits, esaHits) {
+			t.Errorf("Searching for %q in %q, the suffix tree found %v and the ESA %v", p, x, stHits, esaHits)
+		}
+
+		expected := stIntervals(st)
+
+		if intervals := esaIntervals(esa.Intervals); !reflect.DeepEqual(intervals, expected) {
+			t.Errorf("Top-down intervals of %q are %v, expected %v", x, intervals, expected)
+		}
+
+		if intervals := esaIntervals(esa.BottomUp); !reflect.DeepEqual(intervals, expected) {
+			t.Errorf("Bottom-up intervals of %q are %v, expected %v", x, intervals, expected)
+		}
+	})
+}
+
+// In the bottom-up traversal, we see the children of an interval
+// before the interval.
+func TestEsaBottomUpOrder(t *testing.T) {
+	rng := testutils.NewRandomSeed(t)
+
+	testutils.GenerateTestStrings(1, 50, rng, func(x string) {
+		esa := gostr.NewEnhancedSuffixArray(x)
+		seen := map[interval]bool{}
+
+		esa.BottomUp(func(iv gostr.LcpInterval) {
+			iv.Children(func(child gostr.LcpInterval) {
+				if !child.IsLeaf() && !seen[interval{child.Lcp, child.Left, child.Right}] {
+					t.Errorf("Saw %v in %q before its child %v", iv, x, child)
+				}
+			})
+
+			seen[interval{iv.Lcp, iv.Left, iv.Right}] = true
+		})
+	})
+}
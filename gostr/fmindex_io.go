@@ -0,0 +1,523 @@
+package gostr
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+/*
+ The on-disk format for FMIndexTables.
+
+ All integers are little-endian. The file starts with a 16 byte header:
+
+	magic     [8]byte  "GOSTRFMI"
+	version   uint32   the format version, currently 1
+	sections  uint32   the number of sections that follow
+
+ and then the sections. Each section has a 16 byte header, a payload, zero
+ padding up to a multiple of eight bytes, and an eight byte trailer:
+
+	kind      uint32   what the section holds (see the section constants)
+	reserved  uint32   zero
+	length    uint64   the length of the payload in bytes
+	payload   [length]byte
+	padding   zeros up to a multiple of eight bytes
+	checksum  uint32   CRC-32 (Castagnoli) of the payload
+	reserved  uint32   zero
+
+ Inside the payloads, arrays are stored as a uint64 length followed by the
+ elements, and arrays of 32-bit values are padded to a multiple of eight
+ bytes. Since the headers are a multiple of eight bytes, every array
+ starts at an offset in the file that is a multiple of eight.
+
+ The payloads are:
+
+	alphabet:     [256]byte map, [256]byte reverse map, uint64 size
+	suffix array: []int32
+	sampled SA:   uint64 k, bit vector, []int32 samples
+	c-table:      []int64
+	o-tables:     uint64 kind, then for each kind
+	                dense:   uint64 rows, uint64 columns, []int64 table
+	                sampled: uint64 k, uint64 rows, and a bit vector per row
+	                wavelet: uint64 length, uint64 levels, and for
+	                         each level uint64 zeros and a bit vector
+
+ where bit vectors are
+
+	uint64 length (in bits), uint64 words per block, []uint64 words,
+	[]int32 checkpoints
+
+ The alphabet section is required; the rest are optional, so a file can
+ hold just a suffix array.
+*/
+
+// IndexFormatVersion is the version of the on-disk index format
+// that we write.
+const IndexFormatVersion = 1
+
+const (
+	indexMagic           = "GOSTRFMI"
+	indexHeaderSize      = 16
+	sectionHeaderSize    = 16
+	sectionTrailerSize   = 8
+	alphabetPayloadSize  = 256 + 256 + 8
+	defaultSectionBuffer = 4096
+)
+
+// Section kinds
+const (
+	sectionAlphabet uint32 = iota + 1
+	sectionSA
+	sectionSampledSA
+	sectionCTab
+	sectionOTab
+	sectionROTab
+)
+
+// O-table kinds
+const (
+	otabDense uint64 = iota + 1
+	otabSampled
+	otabWavelet
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli) //nolint:gochecknoglobals // a constant table
+
+type indexSection struct {
+	kind   uint32
+	encode func(bw *binWriter)
+}
+
+// writeSection writes a section with its header and trailer. We run the
+// encoder twice, first to get the length of the payload and then to
+// write it, so we never need the encoded payload in memory.
+func writeSection(bw *binWriter, sec indexSection) {
+	counter := &binWriter{w: io.Discard, buf: bw.buf}
+	sec.encode(counter)
+
+	bw.uint32(sec.kind)
+	bw.uint32(0)
+	bw.uint64(uint64(counter.n))
+
+	crc := crc32.New(crcTable)
+	payload := &binWriter{w: io.MultiWriter(bw.w, crc), buf: bw.buf}
+	sec.encode(payload)
+	bw.n += payload.n
+
+	bw.pad()
+	bw.uint32(crc.Sum32())
+	bw.uint32(0)
+}
+
+func encodeBitVector(bw *binWriter, bv *rankBitVector) {
+	bw.int(bv.length)
+	bw.int(bv.blockWords)
+	bw.uint64s(bv.words)
+	bw.int32s(bv.checkpoints)
+}
+
+func encodeOTab(bw *binWriter, otab RankTable) {
+	switch tab := otab.(type) {
+	case *OTab:
+		bw.uint64(otabDense)
+		bw.int(tab.nrow)
+		bw.int(tab.ncol)
+		bw.ints(tab.table)
+
+	case *SampledOTab:
+		bw.uint64(otabSampled)
+		bw.int(tab.K)
+		bw.int(len(tab.rows))
+
+		for _, row := range tab.rows {
+			encodeBitVector(bw, row)
+		}
+
+	case *WaveletMatrix:
+		bw.uint64(otabWavelet)
+		bw.int(tab.length)
+		bw.int(len(tab.levels))
+
+		for l, level := range tab.levels {
+			bw.int(tab.zeros[l])
+			encodeBitVector(bw, level)
+		}
+
+	default:
+		checkError(fmt.Errorf("cannot serialise an o-table of type %T", otab)) //nolint:goerr113 // a one-off
+	}
+}
+
+func (tbls *FMIndexTables) sections() []indexSection {
+	secs := []indexSection{
+		{sectionAlphabet, func(bw *binWriter) {
+			bw.write(tbls.Alpha._map[:])
+			bw.write(tbls.Alpha._revmap[:])
+			bw.int(tbls.Alpha.size)
+		}},
+	}
+
+	if tbls.Sa != nil {
+		secs = append(secs, indexSection{sectionSA, func(bw *binWriter) {
+			bw.int32s(tbls.Sa)
+		}})
+	}
+
+	if tbls.Ssa != nil {
+		secs = append(secs, indexSection{sectionSampledSA, func(bw *binWriter) {
+			bw.int(tbls.Ssa.K)
+			encodeBitVector(bw, tbls.Ssa.marked)
+			bw.int32s(tbls.Ssa.samples)
+		}})
+	}
+
+	if tbls.Ctab != nil {
+		secs = append(secs, indexSection{sectionCTab, func(bw *binWriter) {
+			bw.ints(tbls.Ctab.CumSum)
+		}})
+	}
+
+	if tbls.Otab != nil {
+		secs = append(secs, indexSection{sectionOTab, func(bw *binWriter) {
+			encodeOTab(bw, tbls.Otab)
+		}})
+	}
+
+	if tbls.Rotab != nil {
+		secs = append(secs, indexSection{sectionROTab, func(bw *binWriter) {
+			encodeOTab(bw, tbls.Rotab)
+		}})
+	}
+
+	return secs
+}
+
+// WriteTo writes the tables to w in the gostr binary index format.
+// It implements the io.WriterTo interface.
+func (tbls *FMIndexTables) WriteTo(w io.Writer) (n int64, err error) {
+	bw := newBinWriter(w)
+
+	defer func() { n = bw.n }()
+	defer catchError(&err)
+
+	secs := tbls.sections()
+
+	bw.write([]byte(indexMagic))
+	bw.uint32(IndexFormatVersion)
+	bw.uint32(uint32(len(secs)))
+
+	for _, sec := range secs {
+		writeSection(bw, sec)
+	}
+
+	return bw.n, nil
+}
+
+// readFull reads exactly len(b) bytes, reporting a TruncatedIndex
+// error if the reader runs dry.
+func readFull(r io.Reader, b []byte) {
+	if _, err := io.ReadFull(r, b); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF { //nolint:errorlint // ReadFull doesn't wrap these
+			checkError(NewTruncatedIndex())
+		}
+
+		checkError(err)
+	}
+}
+
+// readIndexHeader reads and checks the header, and returns the number of sections.
+func readIndexHeader(header []byte) int {
+	if magic := string(header[:len(indexMagic)]); magic != indexMagic {
+		checkError(NewInvalidIndexMagic(magic))
+	}
+
+	if version := binary.LittleEndian.Uint32(header[8:]); version != IndexFormatVersion {
+		checkError(NewUnsupportedIndexVersion(version))
+	}
+
+	return int(binary.LittleEndian.Uint32(header[12:]))
+}
+
+// sectionHeader decodes a section header into the kind and payload length.
+func sectionHeader(header []byte) (kind uint32, length int64) {
+	kind = binary.LittleEndian.Uint32(header)
+	length = int64(binary.LittleEndian.Uint64(header[8:]))
+
+	if length < 0 {
+		checkError(NewCorruptIndex("invalid section length"))
+	}
+
+	return kind, length
+}
+
+// checkSection compares the checksum in a section trailer against the payload.
+func checkSection(kind uint32, payload, trailer []byte) {
+	checksum := binary.LittleEndian.Uint32(trailer[len(trailer)-sectionTrailerSize:])
+	if checksum != crc32.Checksum(payload, crcTable) {
+		checkError(NewIndexChecksumMismatch(kind))
+	}
+}
+
+// readSection reads the next section from r and checks its checksum.
+func readSection(r io.Reader) (kind uint32, payload []byte) {
+	header := make([]byte, sectionHeaderSize)
+	readFull(r, header)
+
+	kind, length := sectionHeader(header)
+
+	// We don't trust the length enough to allocate it all up front;
+	// a corrupt length shouldn't make us allocate a huge buffer.
+	var buf bytes.Buffer
+
+	buf.Grow(defaultSectionBuffer)
+
+	if n, err := io.CopyN(&buf, r, length); n != length {
+		if err == io.EOF { //nolint:errorlint // CopyN doesn't wrap EOF
+			checkError(NewTruncatedIndex())
+		}
+
+		checkError(err)
+	}
+
+	payload = buf.Bytes()
+
+	trailer := make([]byte, paddingFor(length)+sectionTrailerSize)
+	readFull(r, trailer)
+	checkSection(kind, payload, trailer)
+
+	return kind, payload
+}
+
+// indexSlicer cuts an index that is already in memory into
+// its header and sections.
+type indexSlicer struct {
+	data []byte
+	pos  int64
+}
+
+func (s *indexSlicer) next(n int64) []byte {
+	if n > int64(len(s.data))-s.pos {
+		checkError(NewTruncatedIndex())
+	}
+
+	b := s.data[s.pos : s.pos+n]
+	s.pos += n
+
+	return b
+}
+
+// section returns the next section and checks its checksum. The
+// payload is a slice of the data, not a copy.
+func (s *indexSlicer) section() (kind uint32, payload []byte) {
+	kind, length := sectionHeader(s.next(sectionHeaderSize))
+	payload = s.next(length)
+	checkSection(kind, payload, s.next(paddingFor(length)+sectionTrailerSize))
+
+	return kind, payload
+}
+
+func decodeBitVector(d *binDecoder) *rankBitVector {
+	bv := &rankBitVector{
+		length:     d.int(),
+		blockWords: d.int(),
+	}
+	bv.words = d.uint64s()
+	bv.checkpoints = d.int32s()
+
+	if bv.blockWords < 1 ||
+		len(bv.words) != (bv.length+wordBits-1)/wordBits ||
+		len(bv.checkpoints) != len(bv.words)/bv.blockWords+1 {
+		checkError(NewCorruptIndex("inconsistent bit vector"))
+	}
+
+	return bv
+}
+
+func decodeOTab(d *binDecoder) RankTable {
+	switch d.uint64() {
+	case otabDense:
+		otab := &OTab{nrow: d.int(), ncol: d.int()}
+		otab.table = d.ints()
+
+		if len(otab.table) != otab.nrow*otab.ncol {
+			checkError(NewCorruptIndex("inconsistent o-table"))
+		}
+
+		return otab
+
+	case otabSampled:
+		otab := &SampledOTab{K: d.int()}
+		otab.rows = make([]*rankBitVector, d.count(1))
+
+		for i := range otab.rows {
+			otab.rows[i] = decodeBitVector(d)
+		}
+
+		return otab
+
+	case otabWavelet:
+		wm := &WaveletMatrix{length: d.int()}
+		noLevels := d.count(1)
+		wm.levels = make([]*rankBitVector, noLevels)
+		wm.zeros = make([]int, noLevels)
+
+		for l := range wm.levels {
+			wm.zeros[l] = d.int()
+			wm.levels[l] = decodeBitVector(d)
+
+			if wm.levels[l].length != wm.length || wm.zeros[l] > wm.length {
+				checkError(NewCorruptIndex("inconsistent wavelet matrix"))
+			}
+		}
+
+		return wm
+
+	default:
+		checkError(NewCorruptIndex("unknown o-table kind"))
+	}
+
+	return nil // not reached, checkError panics
+}
+
+// decodeSection decodes the payload of a section and puts the result in tbls.
+func (tbls *FMIndexTables) decodeSection(kind uint32, d *binDecoder) {
+	switch kind {
+	case sectionAlphabet:
+		if len(d.data) != alphabetPayloadSize {
+			checkError(NewCorruptIndex("alphabet section has the wrong size"))
+		}
+
+		tbls.Alpha = &Alphabet{}
+		copy(tbls.Alpha._map[:], d.next(len(tbls.Alpha._map)))
+		copy(tbls.Alpha._revmap[:], d.next(len(tbls.Alpha._revmap)))
+		tbls.Alpha.size = d.int()
+
+	case sectionSA:
+		tbls.Sa = d.int32s()
+
+	case sectionSampledSA:
+		tbls.Ssa = &SampledSA{K: d.int()}
+		tbls.Ssa.marked = decodeBitVector(d)
+		tbls.Ssa.samples = d.int32s()
+
+		if len(tbls.Ssa.samples) != tbls.Ssa.marked.rank(tbls.Ssa.marked.length) {
+			checkError(NewCorruptIndex("inconsistent sampled suffix array"))
+		}
+
+	case sectionCTab:
+		tbls.Ctab = &CTab{CumSum: d.ints()}
+
+	case sectionOTab:
+		tbls.Otab = decodeOTab(d)
+
+	case sectionROTab:
+		tbls.Rotab = decodeOTab(d)
+
+	default:
+		checkError(NewCorruptIndex(fmt.Sprintf("unknown section kind %d", kind)))
+	}
+
+	d.done()
+}
+
+// validate checks that the tables we read fit together.
+func (tbls *FMIndexTables) validate() {
+	if tbls.Alpha == nil {
+		checkError(NewCorruptIndex("missing alphabet"))
+	}
+
+	if tbls.Alpha.size < 1 || tbls.Alpha.size > len(tbls.Alpha._map) {
+		checkError(NewCorruptIndex("invalid alphabet size"))
+	}
+
+	if tbls.Ctab != nil && len(tbls.Ctab.CumSum) != tbls.Alpha.size {
+		checkError(NewCorruptIndex("c-table doesn't match the alphabet"))
+	}
+
+	if tbls.Sa != nil && tbls.Ssa != nil {
+		checkError(NewCorruptIndex("both a full and a sampled suffix array"))
+	}
+
+	if tbls.Ssa != nil && (tbls.Ctab == nil || tbls.Otab == nil) {
+		checkError(NewCorruptIndex("a sampled suffix array needs the c- and o-tables"))
+	}
+
+	n := tbls.saLen()
+
+	for _, otab := range []RankTable{tbls.Otab, tbls.Rotab} {
+		if otab != nil && !rankTableFits(otab, n, tbls.Alpha.size) {
+			checkError(NewCorruptIndex("o-table doesn't match the suffix array"))
+		}
+	}
+}
+
+func rankTableFits(otab RankTable, n, asize int) bool {
+	switch tab := otab.(type) {
+	case *OTab:
+		return tab.nrow == asize-1 && tab.ncol == n
+
+	case *SampledOTab:
+		if len(tab.rows) != asize-1 {
+			return false
+		}
+
+		for _, row := range tab.rows {
+			if row.length != n {
+				return false
+			}
+		}
+
+		return true
+
+	case *WaveletMatrix:
+		return tab.length == n && 1<<len(tab.levels) >= asize
+	}
+
+	return false
+}
+
+// ReadFMIndex reads tables that were written with FMIndexTables.WriteTo.
+// Files that are not in the format, or that are truncated or corrupted,
+// give an InvalidIndexMagic, UnsupportedIndexVersion, TruncatedIndex,
+// IndexChecksumMismatch, or CorruptIndex error.
+func ReadFMIndex(r io.Reader) (tbls *FMIndexTables, err error) {
+	defer catchError(&err)
+
+	header := make([]byte, indexHeaderSize)
+	readFull(r, header)
+
+	noSections := readIndexHeader(header)
+	res := &FMIndexTables{}
+
+	for i := 0; i < noSections; i++ {
+		kind, payload := readSection(r)
+		res.decodeSection(kind, &binDecoder{data: payload})
+	}
+
+	res.validate()
+
+	return res, nil
+}
+
+// parseFMIndex parses an index that is already in memory. If alias is
+// true, the arrays in the tables will point into data where possible,
+// instead of being copies, so data must outlive the tables.
+func parseFMIndex(data []byte, alias bool) (tbls *FMIndexTables, err error) {
+	defer catchError(&err)
+
+	s := &indexSlicer{data: data}
+	noSections := readIndexHeader(s.next(indexHeaderSize))
+	res := &FMIndexTables{}
+
+	for i := 0; i < noSections; i++ {
+		kind, payload := s.section()
+		res.decodeSection(kind, &binDecoder{data: payload, alias: alias})
+	}
+
+	res.validate()
+
+	return res, nil
+}
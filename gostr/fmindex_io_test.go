@@ -0,0 +1,161 @@
+package gostr_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/mailund/gostr/gostr"
+	"github.com/mailund/gostr/testutils"
+)
+
+var indexConfigs = map[string][]gostr.FMIndexOption{ //nolint:gochecknoglobals // test configurations
+	"Default":     {},
+	"SampledSA":   {gostr.WithSASampling(4)},
+	"SampledOTab": {gostr.WithOTabSampling(64)},
+	"Wavelet":     {gostr.WithWaveletOTab(), gostr.WithSASampling(3)},
+}
+
+func writeIndex(t *testing.T, tbls *gostr.FMIndexTables) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	n, err := tbls.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if n != int64(buf.Len()) {
+		t.Fatalf("WriteTo reported %d bytes but wrote %d", n, buf.Len())
+	}
+
+	return buf.Bytes()
+}
+
+func TestFMIndexRoundTrip(t *testing.T) {
+	rng := testutils.NewRandomSeed(t)
+
+	for name, opts := range indexConfigs {
+		opts := opts
+
+		t.Run(name, func(t *testing.T) {
+			for i := 0; i < 10; i++ {
+				x := testutils.RandomStringRange(1, 1000, "acgt", rng)
+
+				for _, tbls := range []*gostr.FMIndexTables{
+					gostr.BuildFMIndexExactTables(x, opts...),
+					gostr.BuildFMIndexApproxTables(x, opts...),
+				} {
+					data := writeIndex(t, tbls)
+
+					if len(data)%8 != 0 {
+						t.Errorf("The index should be a multiple of eight bytes long, it is %d", len(data))
+					}
+
+					read, err := gostr.ReadFMIndex(bytes.NewReader(data))
+					if err != nil {
+						t.Fatalf("Unexpected error: %s", err)
+					}
+
+					if !reflect.DeepEqual(tbls, read) {
+						t.Fatalf("The tables we read are not the tables we wrote")
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestFMIndexSuffixArrayOnly(t *testing.T) {
+	x := "mississippi"
+	alpha := gostr.NewAlphabet(x)
+	sa, _ := gostr.SaisWithAlphabet(x, alpha)
+	tbls := &gostr.FMIndexTables{Alpha: alpha, Sa: sa}
+
+	read, err := gostr.ReadFMIndex(bytes.NewReader(writeIndex(t, tbls)))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if !reflect.DeepEqual(tbls, read) {
+		t.Errorf("The tables we read are not the tables we wrote")
+	}
+}
+
+func TestFMIndexReadErrors(t *testing.T) {
+	data := writeIndex(t, gostr.BuildFMIndexApproxTables("mississippi"))
+
+	modified := func(f func(b []byte)) []byte {
+		b := make([]byte, len(data))
+		copy(b, data)
+		f(b)
+
+		return b
+	}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr error
+	}{
+		{
+			"Bad magic",
+			modified(func(b []byte) { copy(b, "NOTANFMI") }),
+			gostr.NewInvalidIndexMagic("NOTANFMI"),
+		},
+		{
+			"Bad version",
+			modified(func(b []byte) { binary.LittleEndian.PutUint32(b[8:], 42) }),
+			gostr.NewUnsupportedIndexVersion(42),
+		},
+		{
+			"Flipped payload bit",
+			// The first section is the alphabet, so its payload starts at 32
+			modified(func(b []byte) { b[32+100] ^= 1 }),
+			gostr.NewIndexChecksumMismatch(1),
+		},
+		{
+			"Empty",
+			[]byte{},
+			gostr.NewTruncatedIndex(),
+		},
+		{
+			"Truncated header",
+			data[:10],
+			gostr.NewTruncatedIndex(),
+		},
+		{
+			"Truncated section",
+			data[:100],
+			gostr.NewTruncatedIndex(),
+		},
+		{
+			"Truncated trailer",
+			data[:len(data)-4],
+			gostr.NewTruncatedIndex(),
+		},
+		{
+			"Unknown section",
+			modified(func(b []byte) { binary.LittleEndian.PutUint32(b[16:], 99) }),
+			gostr.NewCorruptIndex("unknown section kind 99"),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			tbls, err := gostr.ReadFMIndex(bytes.NewReader(tt.data))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Expected error %q, got %q", tt.wantErr, err)
+			}
+
+			if tbls != nil {
+				t.Errorf("We should not get tables when there is an error")
+			}
+		})
+	}
+}
@@ -10,3 +10,15 @@ func ReverseString(s string) string {
 
 	return string(runes)
 }
+
+// reverseBytes returns a new string with the bytes of s in reverse
+// order. Unlike ReverseString, it doesn't care about runes, so it is
+// what we need for the algorithms that work on bytes.
+func reverseBytes(s string) string {
+	b := []byte(s)
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+
+	return string(b)
+}
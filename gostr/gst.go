@@ -0,0 +1,191 @@
+package gostr
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Generalized suffix trees. A generalized suffix tree holds all the
+// suffixes of a set of strings, so a search finds the occurrences in
+// all of them at once. We put the strings, each with its own sentinel,
+// into one byte slice, and then it is as if each string had a unique
+// terminator: the edge labels never run past the sentinel at the end
+// of a string, so no path in the tree spans two strings. The leaves
+// index into the concatenation, and we map a leaf back to a string
+// and an offset with the start positions of the strings.
+//
+// Two strings can share a suffix, and then the suffixes have the same
+// path, all the way down to the sentinel. There is only room for one
+// leaf there, so it is the leaf for the first of them, and we keep the
+// others in a table next to the tree.
+
+// GSTHit is an occurrence in a generalized suffix tree: the index of
+// the string it is in and the offset into that string.
+type GSTHit struct {
+	String, Offset int
+}
+
+// GeneralizedSuffixTree is a suffix tree over several strings. The
+// embedded tree's String is the mapped strings, each followed by the
+// sentinel, and Starts[i] is where string i begins. The leaf indices
+// are positions in String.
+type GeneralizedSuffixTree struct {
+	SuffixTree
+	Starts []int
+
+	// The suffixes that have the same path as a leaf, indexed by the
+	// leaf's index.
+	shared map[int][]int
+}
+
+// NewGeneralizedSuffixTree builds a generalized suffix tree for xs,
+// inserting the strings one at a time with McCreight's algorithm.
+func NewGeneralizedSuffixTree(xs ...string) *GeneralizedSuffixTree {
+	alpha := NewAlphabet(strings.Join(xs, ""))
+	gst := &GeneralizedSuffixTree{
+		SuffixTree: SuffixTree{Alpha: alpha, String: []byte{}},
+		Starts:     make([]int, len(xs)),
+		shared:     map[int][]int{},
+	}
+
+	for i, x := range xs {
+		xb, err := alpha.MapToBytesWithSentinel(x)
+		checkError(err) // the alphabet contains all the strings
+
+		gst.Starts[i] = len(gst.String)
+		gst.String = append(gst.String, xb...)
+	}
+
+	gst.Root = gst.newInner(gst.String[0:0])
+	gst.Root.Inner().SuffixLink = gst.Root.Inner()
+
+	for i, start := range gst.Starts {
+		gst.insert(start, start+len(xs[i])+1)
+	}
+
+	return gst
+}
+
+// addSuffix slow-scans for z from n, and adds the leaf for suffix i
+// where the scan ends. If the suffix is already in the tree, from an
+// earlier string, we don't add a leaf, but record i with the leaf
+// that is there. It returns the leaf with the suffix's path.
+func (gst *GeneralizedSuffixTree) addSuffix(n STNode, z []byte, i int) STNode {
+	v, depth, w := sscan(n, z)
+
+	switch {
+	case depth == 0:
+		// Landed on a node
+		leaf := gst.newLeaf(i, w)
+		v.Inner().addChild(leaf)
+
+		return leaf
+
+	case depth == len(v.Shared().EdgeLabel):
+		// Matched the whole suffix, sentinel included, so v is a leaf
+		first := v.Leaf().Index
+		gst.shared[first] = append(gst.shared[first], i)
+
+		return v
+
+	default:
+		// Landed on an edge
+		return gst.breakEdge(v, depth, i, w[depth:])
+	}
+}
+
+// insert adds the suffixes of the string from start to end, sentinel
+// included. It is the loop from McCreight, except that the first
+// suffix can end anywhere in the tree, and that a suffix might already
+// be there.
+func (gst *GeneralizedSuffixTree) insert(start, end int) {
+	xb := gst.String[start:end:end]
+	currLeaf := gst.addSuffix(gst.Root, xb, start)
+
+	for i := 1; i < len(xb); i++ {
+		var (
+			z     []byte
+			ynode STNode
+		)
+
+		p := currLeaf.Shared().Parent
+
+		if p.SuffixLink != nil {
+			z = currLeaf.Shared().suffix()
+			ynode = wrapInner(p.SuffixLink)
+		} else {
+			y := p.suffix()
+			z = currLeaf.Shared().EdgeLabel
+
+			var depth int
+
+			ynode, depth, _ = fscan(wrapInner(p.Parent.SuffixLink), y)
+			if depth < len(ynode.Shared().EdgeLabel) {
+				// ended on an edge
+				currLeaf = gst.breakEdge(ynode, depth, start+i, z)
+				p.SuffixLink = currLeaf.Shared().Parent
+
+				continue
+			}
+
+			p.SuffixLink = ynode.Inner()
+		}
+
+		currLeaf = gst.addSuffix(ynode, z, start+i)
+	}
+}
+
+// Locate maps a position in String to the string it is in and the
+// offset into that string.
+func (gst *GeneralizedSuffixTree) Locate(pos int) GSTHit {
+	// The last string that starts at or before pos
+	i := sort.Search(len(gst.Starts), func(j int) bool { return gst.Starts[j] > pos }) - 1
+	return GSTHit{String: i, Offset: pos - gst.Starts[i]}
+}
+
+// LeafHits maps fn over all the occurrences in the subtree rooted at
+// n, including the suffixes that share a leaf.
+func (gst *GeneralizedSuffixTree) LeafHits(n STNode, fn func(GSTHit)) {
+	n.LeafIndices(func(i int) {
+		fn(gst.Locate(i))
+
+		for _, j := range gst.shared[i] {
+			fn(gst.Locate(j))
+		}
+	})
+}
+
+// Search maps visitor through all the occurrences of p in the
+// strings, with the index of the string and the offset into it.
+func (gst *GeneralizedSuffixTree) Search(p string, visitor func(id, offset int)) {
+	pb, err := gst.Alpha.MapToBytes(p)
+	if err != nil {
+		// We can't map, so no hits
+		return
+	}
+
+	n, depth, y := sscan(gst.Root, pb)
+	if depth == len(y) {
+		gst.LeafHits(n, func(hit GSTHit) { visitor(hit.String, hit.Offset) })
+	}
+}
+
+// ToDot writes a dot representation of the tree to the output writer
+// w. We label the leaves with the string and offset of each suffix
+// they hold.
+func (gst *GeneralizedSuffixTree) ToDot(w io.Writer) {
+	label := func(v *LeafNode) string {
+		hits := []string{}
+		gst.LeafHits(wrapLeaf(v), func(hit GSTHit) {
+			hits = append(hits, fmt.Sprintf("%d:%d", hit.String, hit.Offset))
+		})
+
+		return fmt.Sprintf("%q", strings.Join(hits, ", "))
+	}
+
+	fmt.Fprintln(w, `digraph { rankdir="LR" `)
+	gst.Root.toDot(gst.Alpha, w, label)
+	fmt.Fprintln(w, "}")
+}
@@ -0,0 +1,183 @@
+package gostr_test
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/mailund/gostr/gostr"
+	"github.com/mailund/gostr/testutils"
+)
+
+// gstHits runs the naive search in each string on its own, so we know
+// which hits the generalized suffix tree should give us.
+func gstHits(xs []string, p string) []string {
+	hits := []string{}
+
+	for id, x := range xs {
+		gostr.Naive(x, p, func(i int) {
+			hits = append(hits, fmt.Sprintf("%d:%d", id, i))
+		})
+	}
+
+	sort.Strings(hits)
+
+	return hits
+}
+
+func gstSearch(gst *gostr.GeneralizedSuffixTree, p string) []string {
+	hits := []string{}
+
+	gst.Search(p, func(id, offset int) {
+		hits = append(hits, fmt.Sprintf("%d:%d", id, offset))
+	})
+
+	sort.Strings(hits)
+
+	return hits
+}
+
+// checkGST checks that every suffix of every string has a leaf whose
+// path label is the suffix, and that the inner nodes branch.
+func checkGST(t *testing.T, xs []string, gst *gostr.GeneralizedSuffixTree) {
+	t.Helper()
+
+	seen := map[gostr.GSTHit]bool{}
+
+	var check func(n gostr.STNode)
+	check = func(n gostr.STNode) {
+		if n.NodeType == gostr.Inner {
+			children := 0
+
+			for _, child := range n.Inner().Children {
+				if !child.IsNil() {
+					children++
+
+					check(child)
+				}
+			}
+
+			if n.Inner().Parent != nil && children < 2 {
+				t.Errorf("Inner node %q has only %d children", n.PathLabel(gst.Alpha), children)
+			}
+
+			return
+		}
+
+		label := n.PathLabel(gst.Alpha)
+
+		gst.LeafHits(n, func(hit gostr.GSTHit) {
+			if seen[hit] {
+				t.Errorf("Suffix %v is in the tree twice", hit)
+			}
+
+			seen[hit] = true
+
+			if expected := xs[hit.String][hit.Offset:] + "𝕊"; label != expected {
+				t.Errorf("Leaf %v has path label %q, expected %q", hit, label, expected)
+			}
+		})
+	}
+
+	check(gst.Root)
+
+	for id, x := range xs {
+		for i := 0; i <= len(x); i++ {
+			if !seen[gostr.GSTHit{String: id, Offset: i}] {
+				t.Errorf("Suffix %d of string %d (%q) is not in the tree", i, id, x)
+			}
+		}
+	}
+}
+
+func TestGSTConstruction(t *testing.T) {
+	tests := [][]string{
+		{},
+		{""},
+		{"", ""},
+		{"mississippi"},
+		{"abab", "baba"},
+		{"aaa", "aa", "a", "aaa"},
+		{"mississippi", "missouri", "ississippi"},
+	}
+
+	for _, xs := range tests {
+		gst := gostr.NewGeneralizedSuffixTree(xs...)
+		checkGST(t, xs, gst)
+		checkSuffixLinks(t, "GST", &gst.SuffixTree, gst.Root)
+	}
+}
+
+func TestGSTSearch(t *testing.T) {
+	xs := []string{"mississippi", "missouri", "", "sip"}
+	gst := gostr.NewGeneralizedSuffixTree(xs...)
+
+	tests := []struct {
+		p        string
+		expected []string
+	}{
+		{"miss", []string{"0:0", "1:0"}},
+		{"ssi", []string{"0:2", "0:5"}},
+		{"si", []string{"0:3", "0:6", "3:0"}},
+		{"ip", []string{"0:7", "3:1"}},
+		{"ouri", []string{"1:4"}},
+		{"sipx", []string{}},
+		{"pim", []string{}}, // not across the strings
+	}
+
+	for _, tt := range tests {
+		if hits := gstSearch(gst, tt.p); !reflect.DeepEqual(hits, tt.expected) {
+			t.Errorf("Searching for %q found %v, expected %v", tt.p, hits, tt.expected)
+		}
+	}
+}
+
+func TestGSTRandom(t *testing.T) {
+	rng := testutils.NewRandomSeed(t)
+
+	for n := 0; n < 50; n++ {
+		xs := make([]string, 1+rng.Intn(5))
+		for i := range xs {
+			xs[i] = testutils.RandomStringRange(0, 30, "acg", rng)
+		}
+
+		// Repeat some of the strings, so suffixes are shared
+		if rng.Intn(2) == 0 {
+			xs = append(xs, xs[rng.Intn(len(xs))])
+		}
+
+		gst := gostr.NewGeneralizedSuffixTree(xs...)
+		checkGST(t, xs, gst)
+		checkSuffixLinks(t, "GST", &gst.SuffixTree, gst.Root)
+
+		for k := 0; k < 10; k++ {
+			p := testutils.RandomStringRange(1, 5, "acg", rng)
+			if hits, expected := gstSearch(gst, p), gstHits(xs, p); !reflect.DeepEqual(hits, expected) {
+				t.Fatalf("Searching for %q in %q found %v, expected %v", p, xs, hits, expected)
+			}
+		}
+	}
+}
+
+func TestGSTToDot(t *testing.T) {
+	gst := gostr.NewGeneralizedSuffixTree("aba", "ba")
+
+	var buf bytes.Buffer
+
+	gst.ToDot(&buf)
+	dot := buf.String()
+
+	if !strings.HasPrefix(dot, "digraph") {
+		t.Errorf("Unexpected dot output:\n%s", dot)
+	}
+
+	// "ba" is a suffix of both strings, so they share a leaf.
+	for _, label := range []string{`[label="0:1, 1:0"]`, `[label="0:3, 1:2"]`, `[label="0:0"]`} {
+		if !strings.Contains(dot, label) {
+			t.Errorf("Expected %s in dot output:\n%s", label, dot)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package gostr
+
+// Hamming finds the occurrences of p in x with at most d mismatches,
+// comparing p to each substring of x of the same length, in O(nm) time.
+//
+// Parameters:
+//   - x: the string we search in.
+//   - p: the string we search for
+//   - d: the largest number of mismatches we allow
+//   - callback: a function called for each occurrence, with the
+//     number of mismatches in it
+func Hamming(x, p string, d int, callback func(i, mismatches int)) {
+	n, m := len(x), len(p)
+	if d < 0 || m > n {
+		return
+	}
+
+	for i := 0; i < n-m+1; i++ {
+		mismatches := 0
+
+		// We give up on a position as soon as it has too many mismatches
+		for j := 0; j < m && mismatches <= d; j++ {
+			if x[i+j] != p[j] {
+				mismatches++
+			}
+		}
+
+		if mismatches <= d {
+			callback(i, mismatches)
+		}
+	}
+}
+
+// mapWithMismatches maps p to the alphabet's bytes, but where
+// MapToBytes fails on a letter that isn't in the alphabet, we map it
+// to the sentinel. No letter in x matches that, so it is always a
+// mismatch, but we can still substitute it.
+func (alpha *Alphabet) mapWithMismatches(p string) []byte {
+	pb := make([]byte, len(p))
+
+	for i := 0; i < len(p); i++ {
+		pb[i] = alpha._map[p[i]]
+	}
+
+	return pb
+}
+
+// buildHammingDtab is buildDtab for patterns where the sentinel stands
+// for letters that aren't in x, and which we can only mismatch.
+func buildHammingDtab(p []byte, tbls *FMIndexTables) []int {
+	dtab := make([]int, len(p))
+
+	minEdits := 0
+	left, right := 0, tbls.saLen()
+
+	for i, a := range p {
+		if a != Sentinel {
+			left = tbls.Ctab.Rank(a) + tbls.Rotab.Rank(a, left)
+			right = tbls.Ctab.Rank(a) + tbls.Rotab.Rank(a, right)
+		}
+
+		if a == Sentinel || left >= right {
+			minEdits++
+
+			left, right = 0, tbls.saLen()
+		}
+
+		dtab[i] = minEdits
+	}
+
+	return dtab
+}
+
+// FMIndexHammingFromTables returns a search function that finds the
+// occurrences of a pattern with at most d mismatches and reports them
+// together with their number of mismatches. It doesn't explore
+// insertions and deletions, so it is much faster than the search from
+// FMIndexApproxFromTables. With approximative tables, it uses the
+// reversed o-table to stop early, but it also works with the exact
+// tables.
+func FMIndexHammingFromTables(tbls *FMIndexTables) func(p string, d int, cb func(i, mismatches int)) {
+	return func(p string, d int, cb func(i, mismatches int)) {
+		if d < 0 {
+			return // no occurrence has fewer than zero mismatches
+		}
+
+		pb := tbls.Alpha.mapWithMismatches(p)
+
+		// D-table for early termination, or all zeros if we don't
+		// have the reversed o-table
+		dtab := make([]int, len(pb))
+		if tbls.Rotab != nil {
+			dtab = buildHammingDtab(pb, tbls)
+		}
+
+		var rec func(i, left, right, mismatches int)
+
+		rec = func(i, left, right, mismatches int) {
+			if i < 0 {
+				for j := left; j < right; j++ {
+					cb(tbls.saLookup(j), mismatches)
+				}
+
+				return
+			}
+
+			if d-mismatches < dtab[i] {
+				return // not sufficient mismatches left
+			}
+
+			for b := 1; b < tbls.Alpha.Size(); b++ {
+				a := byte(b)
+				nextLeft := tbls.Ctab.Rank(a) + tbls.Otab.Rank(a, left)
+				nextRight := tbls.Ctab.Rank(a) + tbls.Otab.Rank(a, right)
+
+				if nextLeft == nextRight {
+					continue
+				}
+
+				if a == pb[i] {
+					rec(i-1, nextLeft, nextRight, mismatches)
+				} else if mismatches < d {
+					rec(i-1, nextLeft, nextRight, mismatches+1)
+				}
+			}
+		}
+
+		rec(len(pb)-1, 0, tbls.saLen(), 0)
+	}
+}
+
+// FMIndexHammingPreprocess preprocesses the string x and returns a
+// function that you can use to efficiently search in x with mismatches.
+func FMIndexHammingPreprocess(x string) func(p string, d int, cb func(i, mismatches int)) {
+	return FMIndexHammingFromTables(BuildFMIndexApproxTables(x))
+}
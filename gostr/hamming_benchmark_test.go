@@ -0,0 +1,31 @@
+package gostr_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mailund/gostr/gostr"
+	"github.com/mailund/gostr/testutils"
+)
+
+func Benchmark_Hamming(b *testing.B) {
+	rng := testutils.NewRandomSeed(b)
+	x := testutils.RandomStringN(100000, "acgt", rng)
+	tbls := gostr.BuildFMIndexApproxTables(x)
+	p := testutils.RandomStringN(30, "acgt", rng)
+
+	for d := 1; d <= 3; d++ {
+		b.Run(fmt.Sprintf("Hamming:d=%d", d), func(b *testing.B) {
+			search := gostr.FMIndexHammingFromTables(tbls)
+			for i := 0; i < b.N; i++ {
+				search(p, d, func(int, int) {})
+			}
+		})
+		b.Run(fmt.Sprintf("Approx:d=%d", d), func(b *testing.B) {
+			search := gostr.FMIndexApproxFromTables(tbls)
+			for i := 0; i < b.N; i++ {
+				search(p, d, func(int, string) {})
+			}
+		})
+	}
+}
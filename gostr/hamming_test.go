@@ -0,0 +1,122 @@
+package gostr_test
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/mailund/gostr/gostr"
+	"github.com/mailund/gostr/testutils"
+)
+
+func collectHammingHits(search func(p string, d int, cb func(i, mismatches int)), p string, d int) []string {
+	hits := []string{}
+
+	search(p, d, func(i, mismatches int) {
+		hits = append(hits, fmt.Sprintf("%d:%d", i, mismatches))
+	})
+	sort.Strings(hits)
+
+	return hits
+}
+
+func TestHamming(t *testing.T) {
+	type args struct {
+		x, p string
+		d    int
+	}
+
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{"Exact", args{"acgtacgt", "acg", 0}, []string{"0:0", "4:0"}},
+		{"One mismatch", args{"acgtacgt", "aag", 1}, []string{"0:1", "4:1"}},
+		{"Two mismatches", args{"aaaa", "cc", 2}, []string{"0:2", "1:2", "2:2"}},
+		{"Negative", args{"aaaa", "a", -1}, []string{}},
+		{"Too long", args{"aa", "aaa", 3}, []string{}},
+		{"Empty pattern", args{"ab", "", 0}, []string{"0:0", "1:0", "2:0"}},
+		{"Letter not in x", args{"acgt", "an", 1}, []string{"0:1"}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			online := func(p string, d int, cb func(i, mismatches int)) { gostr.Hamming(tt.args.x, p, d, cb) }
+			if hits := collectHammingHits(online, tt.args.p, tt.args.d); !reflect.DeepEqual(hits, tt.want) {
+				t.Errorf("Hamming() = %v, want %v", hits, tt.want)
+			}
+
+			fm := gostr.FMIndexHammingPreprocess(tt.args.x)
+			if hits := collectHammingHits(fm, tt.args.p, tt.args.d); !reflect.DeepEqual(hits, tt.want) {
+				t.Errorf("FM-index Hamming = %v, want %v", hits, tt.want)
+			}
+		})
+	}
+}
+
+func TestHammingRandom(t *testing.T) {
+	rng := testutils.NewRandomSeed(t)
+
+	check := func(x string, tbls *gostr.FMIndexTables) {
+		search := gostr.FMIndexHammingFromTables(tbls)
+		online := func(p string, d int, cb func(i, mismatches int)) { gostr.Hamming(x, p, d, cb) }
+
+		for j := 0; j < 5; j++ {
+			p := testutils.RandomStringRange(1, 10, "acgt", rng)
+			if len(x) > 1 && rng.Intn(2) == 0 {
+				p = testutils.PickRandomSubstring(x, rng)
+			}
+
+			d := rng.Intn(4)
+
+			expected := collectHammingHits(online, p, d)
+			if hits := collectHammingHits(search, p, d); !reflect.DeepEqual(hits, expected) {
+				t.Fatalf("Searching for %q in %q with %d mismatches gave\n%v, expected\n%v", p, x, d, hits, expected)
+			}
+		}
+	}
+
+	for i := 0; i < 100; i++ {
+		x := testutils.RandomStringRange(0, 50, "acg", rng)
+		check(x, gostr.BuildFMIndexApproxTables(x))
+		check(x, gostr.BuildFMIndexApproxTables(x, gostr.WithSASampling(3), gostr.WithWaveletOTab()))
+		check(x, gostr.BuildFMIndexExactTables(x, gostr.WithOTabSampling(4)))
+	}
+
+	x := testutils.FibonacciString(10)
+	check(x, gostr.BuildFMIndexApproxTables(x))
+}
+
+// The mismatch-only search should agree with the bidirectional search
+// in its Mismatches mode, which reports the same positions with cigars.
+func TestHammingBidir(t *testing.T) {
+	rng := testutils.NewRandomSeed(t)
+
+	for i := 0; i < 50; i++ {
+		x := testutils.RandomStringRange(1, 50, "acgt", rng)
+		tbls := gostr.BuildFMIndexApproxTables(x)
+		hamming := gostr.FMIndexHammingFromTables(tbls)
+		bidir := gostr.FMIndexBidirFromTables(tbls, gostr.Mismatches)
+		p := testutils.RandomStringRange(1, 8, "acgt", rng)
+		d := rng.Intn(3)
+
+		expected := map[int]bool{}
+		bidir(p, d, func(i int, _ string) { expected[i] = true })
+
+		hits := map[int]bool{}
+		hamming(p, d, func(i, mismatches int) {
+			hits[i] = true
+
+			if mismatches > d {
+				t.Errorf("Hit at %d has %d mismatches, more than %d", i, mismatches, d)
+			}
+		})
+
+		if !reflect.DeepEqual(hits, expected) {
+			t.Fatalf("Searching for %q in %q with %d mismatches gave\n%v, expected\n%v", p, x, d, hits, expected)
+		}
+	}
+}
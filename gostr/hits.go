@@ -0,0 +1,146 @@
+package gostr
+
+import "sort"
+
+// ApproxHit is an approximative occurrence of a pattern: where it
+// starts in the text, how the pattern aligns there, and the number of
+// edits in the alignment.
+type ApproxHit struct {
+	Pos   int
+	Cigar string
+	Edits int
+}
+
+// sortHits sorts hits by position, then by edits, and then by cigar,
+// so the first hit at a position is one of the best there, and we
+// always pick the same one.
+func sortHits(hits []ApproxHit) {
+	sort.Slice(hits, func(i, j int) bool {
+		a, b := hits[i], hits[j]
+
+		switch {
+		case a.Pos != b.Pos:
+			return a.Pos < b.Pos
+		case a.Edits != b.Edits:
+			return a.Edits < b.Edits
+		default:
+			return a.Cigar < b.Cigar
+		}
+	})
+}
+
+// ApproxHits runs an approximative search for p, with up to edits
+// edits, and collects the hits. It uses x, the string the search
+// function searches in, to count the actual edits in each hit, the
+// way CountEdits does, since that can be less than the edits the
+// search allows. The hits are sorted by position, then edits, and then
+// cigar, and if the search reports the same hit more than once, we
+// only keep one of them. If the search reports a cigar that CountEdits
+// can't parse, you get its error.
+func ApproxHits(
+	x, p string, edits int,
+	search func(p string, edits int, cb func(i int, cigar string)),
+) ([]ApproxHit, error) {
+	var (
+		hits = []ApproxHit{}
+		err  error
+	)
+
+	search(p, edits, func(i int, cigar string) {
+		if err != nil {
+			return // we keep the first error
+		}
+
+		var n int
+		if n, err = CountEdits(x, p, i, cigar); err == nil {
+			hits = append(hits, ApproxHit{Pos: i, Cigar: cigar, Edits: n})
+		}
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sortHits(hits)
+
+	res := hits[:0]
+
+	for i, hit := range hits {
+		if i == 0 || hit != hits[i-1] {
+			res = append(res, hit)
+		}
+	}
+
+	return res, nil
+}
+
+// BestPerPosition keeps one hit with the fewest edits for each
+// position. The search finds the same occurrence with different but
+// equivalent cigars, e.g., 1D1M and 1M1D, and with more edits than it
+// needs, and this gets rid of those. If there are several best hits
+// at a position, we keep the one with the smallest cigar, so the
+// result doesn't depend on the order the search reported them in.
+// The result is sorted by position.
+func BestPerPosition(hits []ApproxHit) []ApproxHit {
+	sorted := make([]ApproxHit, len(hits))
+	copy(sorted, hits)
+	sortHits(sorted)
+
+	res := []ApproxHit{}
+
+	for i, hit := range sorted {
+		if i == 0 || hit.Pos != sorted[i-1].Pos {
+			res = append(res, hit)
+		}
+	}
+
+	return res
+}
+
+// BestHits returns the best hit at each position, as BestPerPosition,
+// but only for the positions where the hit has the fewest edits of
+// all the hits. The result is sorted by position.
+func BestHits(hits []ApproxHit) []ApproxHit {
+	best := BestPerPosition(hits)
+	if len(best) == 0 {
+		return best
+	}
+
+	fewest := best[0].Edits
+	for _, hit := range best[1:] {
+		fewest = smallest(fewest, hit.Edits)
+	}
+
+	res := []ApproxHit{}
+
+	for _, hit := range best {
+		if hit.Edits == fewest {
+			res = append(res, hit)
+		}
+	}
+
+	return res
+}
+
+// TopHits returns the n hits with the fewest edits, taking the best
+// hit at each position, as BestPerPosition, so we don't get the same
+// position more than once. The result is sorted by edits, and hits
+// with the same number of edits by position. If there are fewer than
+// n positions, you get all of them.
+func TopHits(hits []ApproxHit, n int) []ApproxHit {
+	best := BestPerPosition(hits)
+
+	sort.SliceStable(best, func(i, j int) bool {
+		return best[i].Edits < best[j].Edits
+	})
+
+	if n < 0 {
+		n = 0
+	}
+
+	if n < len(best) {
+		best = best[:n]
+	}
+
+	return best
+}
@@ -0,0 +1,156 @@
+package gostr_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/mailund/gostr/gostr"
+	"github.com/mailund/gostr/testutils"
+)
+
+// approxHits is ApproxHits for searches we know report valid hits.
+func approxHits(
+	t *testing.T, x, p string, edits int,
+	search func(p string, edits int, cb func(i int, cigar string)),
+) []gostr.ApproxHit {
+	t.Helper()
+
+	hits, err := gostr.ApproxHits(x, p, edits, search)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	return hits
+}
+
+func TestApproxHits(t *testing.T) {
+	x, p := "acgtacgt", "acgt"
+	search := gostr.FMIndexApproxPreprocess(x)
+
+	hits := approxHits(t, x, p, 1, search)
+	for i, hit := range hits {
+		if i > 0 && hits[i-1] == hit {
+			t.Errorf("Hit %v is reported twice", hit)
+		}
+
+		if n, _ := gostr.CountEdits(x, p, hit.Pos, hit.Cigar); n != hit.Edits {
+			t.Errorf("Hit %v should have %d edits", hit, n)
+		}
+	}
+
+	// The exact matches are there, with zero edits, even though the
+	// search allowed one
+	found := map[int]bool{}
+
+	for _, hit := range hits {
+		if hit.Cigar == "4M" && hit.Edits == 0 {
+			found[hit.Pos] = true
+		}
+	}
+
+	if !found[0] || !found[4] {
+		t.Errorf("Expected exact hits at 0 and 4 in %v", hits)
+	}
+}
+
+func TestApproxHitsInvalid(t *testing.T) {
+	x, p := "acgtacgt", "acgt"
+	bogus := func(p string, edits int, cb func(i int, cigar string)) {
+		cb(0, "4M")
+		cb(4, "4Q")
+		cb(4, "4M")
+	}
+
+	if hits, err := gostr.ApproxHits(x, p, 1, bogus); !errors.Is(err, gostr.NewInvalidCigar("4Q", 1)) {
+		t.Errorf("Expected an invalid cigar error, got hits %v and error %v", hits, err)
+	}
+}
+
+func TestBestPerPosition(t *testing.T) {
+	hits := []gostr.ApproxHit{
+		{Pos: 3, Cigar: "1M1D", Edits: 1},
+		{Pos: 1, Cigar: "2M", Edits: 1},
+		{Pos: 3, Cigar: "1D1M", Edits: 1},
+		{Pos: 1, Cigar: "1I1M", Edits: 1},
+		{Pos: 1, Cigar: "2M", Edits: 0},
+		{Pos: 0, Cigar: "2M", Edits: 2},
+	}
+	expected := []gostr.ApproxHit{
+		{Pos: 0, Cigar: "2M", Edits: 2},
+		{Pos: 1, Cigar: "2M", Edits: 0},
+		{Pos: 3, Cigar: "1D1M", Edits: 1},
+	}
+
+	if best := gostr.BestPerPosition(hits); !reflect.DeepEqual(best, expected) {
+		t.Errorf("BestPerPosition() = %v, want %v", best, expected)
+	}
+
+	if best := gostr.BestHits(hits); !reflect.DeepEqual(best, expected[1:2]) {
+		t.Errorf("BestHits() = %v, want %v", best, expected[1:2])
+	}
+
+	top := []gostr.ApproxHit{expected[1], expected[2]}
+	if best := gostr.TopHits(hits, 2); !reflect.DeepEqual(best, top) {
+		t.Errorf("TopHits(2) = %v, want %v", best, top)
+	}
+
+	top = []gostr.ApproxHit{expected[1], expected[2], expected[0]}
+	if best := gostr.TopHits(hits, 10); !reflect.DeepEqual(best, top) {
+		t.Errorf("TopHits(10) = %v, want %v", best, top)
+	}
+
+	if best := gostr.TopHits(hits, 0); len(best) != 0 {
+		t.Errorf("TopHits(0) = %v, want no hits", best)
+	}
+
+	for _, filter := range []func([]gostr.ApproxHit) []gostr.ApproxHit{gostr.BestPerPosition, gostr.BestHits} {
+		if best := filter([]gostr.ApproxHit{}); len(best) != 0 {
+			t.Errorf("Expected no hits from no hits, got %v", best)
+		}
+	}
+}
+
+func TestBestPerPositionRandom(t *testing.T) {
+	rng := testutils.NewRandomSeed(t)
+
+	for i := 0; i < 50; i++ {
+		x := testutils.RandomStringRange(2, 50, "acg", rng)
+		p := testutils.PickRandomSubstring(x, rng)
+		edits := rng.Intn(3)
+		search := gostr.FMIndexApproxPreprocess(x)
+
+		hits := approxHits(t, x, p, edits, search)
+		best := gostr.BestPerPosition(hits)
+
+		// The fewest edits we can find at each position
+		fewest := map[int]int{}
+
+		for _, hit := range hits {
+			if n, ok := fewest[hit.Pos]; !ok || hit.Edits < n {
+				fewest[hit.Pos] = hit.Edits
+			}
+		}
+
+		if len(best) != len(fewest) {
+			t.Fatalf("Expected one hit for each of %v, got %v", fewest, best)
+		}
+
+		for j, hit := range best {
+			if j > 0 && best[j-1].Pos >= hit.Pos {
+				t.Errorf("Hits are not sorted by position: %v", best)
+			}
+
+			if hit.Edits != fewest[hit.Pos] {
+				t.Errorf("Hit %v should have %d edits", hit, fewest[hit.Pos])
+			}
+		}
+
+		// p is a substring of x, so the best hits are exact matches
+		for _, hit := range gostr.BestHits(hits) {
+			if hit.Edits != 0 {
+				t.Errorf("Expected only exact best hits for %q in %q, got %v", p, x, hit)
+			}
+		}
+	}
+}
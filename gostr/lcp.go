@@ -0,0 +1,118 @@
+package gostr
+
+// LCP arrays from suffix arrays. The LCP array holds, at index i, the
+// length of the longest common prefix of the suffixes at sa[i-1] and
+// sa[i], and zero at index 0. All the algorithms work on suffix arrays
+// with the sentinel suffix, as Sais and Skew build them, and without.
+// The sentinel suffix is empty, so it shares no prefix with any other
+// suffix.
+//
+// - Kasai et al., Linear-Time Longest-Common-Prefix Computation in
+//   Suffix Arrays and Its Applications (2001).
+// - Kärkkäinen et al., Permuted Longest-Common-Prefix Array (2009).
+
+// extendLcp returns the length of the longest common prefix of x[i:]
+// and x[j:], when we already know that it is at least h.
+func extendLcp(x string, i, j, h int32) int32 {
+	n := int32(len(x))
+	for i+h < n && j+h < n && x[i+h] == x[j+h] {
+		h++
+	}
+
+	return h
+}
+
+// LcpKasai computes the LCP array for the suffix array sa over x with
+// Kasai's algorithm. It goes through the suffixes in the order they
+// have in x, and since the LCP of one suffix and the one before it in
+// sa is at most one less than the LCP of the suffix before, we never
+// compare more than O(n) letters in total. Besides the LCP array, it
+// uses an array for the rank of each suffix, i.e., the inverse suffix
+// array.
+func LcpKasai(x string, sa []int32) []int32 {
+	rank := make([]int32, len(sa))
+	for i, j := range sa {
+		rank[j] = int32(i)
+	}
+
+	lcp := make([]int32, len(sa))
+
+	var h int32
+
+	for i := range rank {
+		r := rank[i]
+		if r == 0 {
+			h = 0
+			continue // lcp[0] is zero
+		}
+
+		h = extendLcp(x, int32(i), sa[r-1], h)
+		lcp[r] = h
+
+		if h > 0 {
+			h--
+		}
+	}
+
+	return lcp
+}
+
+// Plcp computes the permuted LCP array for the suffix array sa over x:
+// the LCP array in the order of the suffixes in x instead of the order
+// in sa, so plcp[sa[i]] == lcp[i]. It uses the Φ algorithm, where
+// Φ(sa[i]) = sa[i-1] is the suffix just before sa[i] in the suffix
+// array. We compute Φ into the array we return, and then replace it by
+// the LCP values, working through x from left to right, so the only
+// memory we use is the result.
+func Plcp(x string, sa []int32) []int32 {
+	if len(sa) == 0 {
+		return []int32{}
+	}
+
+	plcp := make([]int32, len(sa))
+
+	// The first suffix has no suffix before it.
+	plcp[sa[0]] = -1
+	for i := 1; i < len(sa); i++ {
+		plcp[sa[i]] = sa[i-1]
+	}
+
+	var h int32
+
+	for i, phi := range plcp {
+		if phi < 0 {
+			h = 0
+			plcp[i] = 0
+
+			continue
+		}
+
+		h = extendLcp(x, int32(i), phi, h)
+		plcp[i] = h
+
+		if h > 0 {
+			h--
+		}
+	}
+
+	return plcp
+}
+
+// LcpFromPlcp permutes the permuted LCP array back into suffix array
+// order.
+func LcpFromPlcp(sa, plcp []int32) []int32 {
+	lcp := make([]int32, len(sa))
+	for i, j := range sa {
+		lcp[i] = plcp[j]
+	}
+
+	return lcp
+}
+
+// LcpPhi computes the LCP array for the suffix array sa over x with
+// the Φ algorithm. With the permuted array and the LCP array, it uses
+// as much memory as Kasai's algorithm, but if you can use the LCP
+// values in the order of x, Plcp alone gives you them in half of that.
+func LcpPhi(x string, sa []int32) []int32 {
+	return LcpFromPlcp(sa, Plcp(x, sa))
+}
@@ -0,0 +1,85 @@
+package gostr_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mailund/gostr/gostr"
+	"github.com/mailund/gostr/testutils"
+)
+
+type LcpAlgo = func(x string, sa []int32) []int32
+
+var lcpAlgorithms = map[string]LcpAlgo{ //nolint:gochecknoglobals // test algorithms
+	"Kasai": gostr.LcpKasai,
+	"Phi":   gostr.LcpPhi,
+}
+
+func TestLcpBasic(t *testing.T) {
+	tests := []struct {
+		name    string
+		x       string
+		wantLcp []int32
+	}{
+		{"Empty string", "", []int32{0}},
+		{"Single letter", "a", []int32{0, 0}},
+		{"Repeated letter", "aaa", []int32{0, 0, 1, 2}},
+		{"mississippi", "mississippi", []int32{0, 0, 1, 1, 4, 0, 0, 1, 0, 2, 1, 3}},
+	}
+
+	for name, algo := range lcpAlgorithms {
+		for _, tt := range tests {
+			tt := tt
+			t.Run(name+"/"+tt.name, func(t *testing.T) {
+				if lcp := algo(tt.x, gostr.Sais(tt.x)); !reflect.DeepEqual(lcp, tt.wantLcp) {
+					t.Errorf("Got = %v, want %v", lcp, tt.wantLcp)
+				}
+			})
+		}
+	}
+}
+
+func TestLcpConsistency(t *testing.T) {
+	for name, algo := range lcpAlgorithms {
+		algo := algo
+		t.Run(name, func(t *testing.T) {
+			rng := testutils.NewRandomSeed(t)
+			testutils.GenerateTestStrings(50, 150, rng,
+				func(x string) {
+					sa := gostr.Skew(x)
+					testutils.CheckLcpArray(t, x, sa, algo(x, sa))
+
+					// Without the sentinel
+					testutils.CheckLcpArray(t, x, sa[1:], algo(x, sa[1:]))
+				})
+		})
+	}
+}
+
+// The LCP arrays we get from the suffix arrays are the same as those we
+// get from the suffix tree.
+func TestLcpSuffixTree(t *testing.T) {
+	rng := testutils.NewRandomSeed(t)
+	testutils.GenerateTestStrings(10, 100, rng,
+		func(x string) {
+			stSa, stLcp := gostr.McCreight(x).ComputeSuffixAndLcpArray()
+			sa := gostr.Sais(x)
+
+			if !reflect.DeepEqual(sa, stSa) {
+				t.Fatalf("Sais and the suffix tree disagree on the suffix array for %q", x)
+			}
+
+			for name, algo := range lcpAlgorithms {
+				if lcp := algo(x, sa); !reflect.DeepEqual(lcp, stLcp) {
+					t.Errorf("%s(%q) = %v, but the suffix tree gives %v", name, x, lcp, stLcp)
+				}
+			}
+
+			plcp := gostr.Plcp(x, sa)
+			for i, j := range sa {
+				if plcp[j] != stLcp[i] {
+					t.Errorf("Plcp(%q)[%d] = %d, expected %d", x, j, plcp[j], stLcp[i])
+				}
+			}
+		})
+}
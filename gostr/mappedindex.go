@@ -0,0 +1,51 @@
+package gostr
+
+import "os"
+
+// MappedFMIndex is an FM-index loaded from a file in the gostr binary
+// index format by memory-mapping it. The suffix array and the rank
+// tables point directly into the mapped pages rather than being copied
+// onto the heap, so processes that open the same file share a single
+// copy of it. On platforms where we do not memory-map files, the file is
+// read into memory instead.
+//
+// The tables are only valid until you call Close.
+type MappedFMIndex struct {
+	*FMIndexTables
+	data []byte
+}
+
+// OpenFMIndex memory-maps the index in the file at path. The file is
+// checked the same way as with ReadFMIndex, so you get the same errors
+// if it is truncated or corrupted.
+func OpenFMIndex(path string) (*MappedFMIndex, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	data, err := mmapFile(f)
+	if err != nil {
+		return nil, err
+	}
+
+	tbls, err := parseFMIndex(data, true)
+	if err != nil {
+		munmap(data) //nolint:errcheck // we report the parse error instead
+
+		return nil, err
+	}
+
+	return &MappedFMIndex{FMIndexTables: tbls, data: data}, nil
+}
+
+// Close unmaps the file. You cannot use the tables after you have
+// closed the index.
+func (idx *MappedFMIndex) Close() error {
+	data := idx.data
+	idx.FMIndexTables, idx.data = nil, nil
+
+	return munmap(data)
+}
@@ -0,0 +1,46 @@
+package gostr
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"unsafe"
+)
+
+func inData(data []byte, p unsafe.Pointer) bool {
+	start := uintptr(unsafe.Pointer(&data[0]))
+	return start <= uintptr(p) && uintptr(p) < start+uintptr(len(data))
+}
+
+func TestMappedIndexAliasing(t *testing.T) {
+	if !nativeLittleEndian {
+		t.Skip("we only alias arrays on little-endian machines")
+	}
+
+	var buf bytes.Buffer
+
+	if _, err := BuildFMIndexExactTables("mississippi").WriteTo(&buf); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "index.fmi")
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	idx, err := OpenFMIndex(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	defer idx.Close()
+
+	if !inData(idx.data, unsafe.Pointer(&idx.Sa[0])) {
+		t.Errorf("The suffix array should point into the mapped file")
+	}
+
+	if otab, ok := idx.Otab.(*OTab); !ok || !inData(idx.data, unsafe.Pointer(&otab.table[0])) {
+		t.Errorf("The o-table should point into the mapped file")
+	}
+}
@@ -0,0 +1,90 @@
+package gostr_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/mailund/gostr/gostr"
+	"github.com/mailund/gostr/testutils"
+)
+
+func writeIndexFile(t *testing.T, tbls *gostr.FMIndexTables) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "index.fmi")
+	if err := os.WriteFile(path, writeIndex(t, tbls), 0o600); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	return path
+}
+
+func TestMappedFMIndex(t *testing.T) {
+	rng := testutils.NewRandomSeed(t)
+
+	for name, opts := range indexConfigs {
+		opts := opts
+
+		t.Run(name, func(t *testing.T) {
+			x := testutils.RandomStringN(2000, "acgt", rng)
+			tbls := gostr.BuildFMIndexApproxTables(x, opts...)
+
+			idx, err := gostr.OpenFMIndex(writeIndexFile(t, tbls))
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+
+			if !reflect.DeepEqual(tbls, idx.FMIndexTables) {
+				t.Fatalf("The mapped tables are not the tables we wrote")
+			}
+
+			search := gostr.FMIndexExactFromTables(tbls)
+			mapped := gostr.FMIndexExactFromTables(idx.FMIndexTables)
+
+			for i := 0; i < 20; i++ {
+				p := testutils.PickRandomSubstring(x, rng)
+
+				expected := collectExactHits(search, p)
+				if hits := collectExactHits(mapped, p); !reflect.DeepEqual(expected, hits) {
+					t.Fatalf("Got hits %v for %q, expected %v", hits, p, expected)
+				}
+			}
+
+			if err := idx.Close(); err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			}
+
+			if idx.FMIndexTables != nil {
+				t.Errorf("The tables should be gone after Close")
+			}
+		})
+	}
+}
+
+func TestMappedFMIndexErrors(t *testing.T) {
+	if _, err := gostr.OpenFMIndex(filepath.Join(t.TempDir(), "missing")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected a missing file error, got %q", err)
+	}
+
+	data := writeIndex(t, gostr.BuildFMIndexExactTables("mississippi"))
+	path := filepath.Join(t.TempDir(), "truncated.fmi")
+
+	if err := os.WriteFile(path, data[:len(data)-8], 0o600); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if _, err := gostr.OpenFMIndex(path); !errors.Is(err, gostr.NewTruncatedIndex()) {
+		t.Errorf("Expected a truncated index error, got %q", err)
+	}
+
+	if err := os.WriteFile(path, []byte{}, 0o600); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if _, err := gostr.OpenFMIndex(path); !errors.Is(err, gostr.NewTruncatedIndex()) {
+		t.Errorf("Expected a truncated index error, got %q", err)
+	}
+}
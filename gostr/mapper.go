@@ -0,0 +1,159 @@
+package gostr
+
+import "sort"
+
+// Seed-and-extend read mapping. The approximative FM-index searches
+// explore all the ways of editing the pattern, which is exponential in
+// the number of edits, so they don't work for long reads with many
+// edits. Instead, we split the read into seeds that we look up
+// exactly, and each seed hit puts the read on a diagonal, the position
+// in x where the read would start if there were no insertions or
+// deletions before the seed. An alignment with k edits stays within k
+// diagonals of each of its seeds, so we verify each diagonal with an
+// alignment in the band k diagonals to either side of it. We don't
+// merge nearby diagonals into one band, since in repeats, the seeds
+// for all the copies are next to each other, and one band would only
+// give us one of them.
+
+// MapperOption is an option you can give to the read mapper.
+type MapperOption func(*mapperConfig)
+
+type mapperConfig struct {
+	seedLen    int
+	seedStride int
+	maxHits    int
+}
+
+func newMapperConfig(opts []MapperOption) *mapperConfig {
+	cfg := &mapperConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return cfg
+}
+
+// seeds returns the offsets and the length of the seeds for a read of
+// length m that we map with up to edits edits.
+func (cfg *mapperConfig) seeds(m, edits int) (offsets []int, seedLen int) {
+	// Unless we are told otherwise, we use the pigeonhole principle:
+	// split the read into edits+1 seeds, and one of them has to match
+	// exactly.
+	seedLen = cfg.seedLen
+	if seedLen <= 0 {
+		seedLen = m / (edits + 1)
+	}
+
+	if seedLen <= 0 || seedLen > m {
+		seedLen = m
+	}
+
+	stride := cfg.seedStride
+	if stride <= 0 {
+		stride = seedLen
+	}
+
+	for i := 0; m > 0 && i+seedLen <= m; i += stride {
+		offsets = append(offsets, i)
+	}
+
+	return offsets, seedLen
+}
+
+// WithSeedLength sets the length of the seeds. By default, the mapper
+// splits a read with up to k edits into k+1 seeds, so one of them must
+// match exactly in any occurrence, as long as the read has more than k
+// letters, and no occurrence is missed. Longer seeds have
+// fewer random hits, so they are faster, but then the mapper can miss
+// occurrences where the edits hit all the seeds.
+func WithSeedLength(n int) MapperOption {
+	return func(cfg *mapperConfig) {
+		cfg.seedLen = n
+	}
+}
+
+// WithSeedStride sets the distance between the starts of the seeds. By
+// default, it is the seed length, so the seeds don't overlap and don't
+// leave gaps between them. A shorter stride gives overlapping seeds, and
+// a longer one spaced seeds.
+func WithSeedStride(n int) MapperOption {
+	return func(cfg *mapperConfig) {
+		cfg.seedStride = n
+	}
+}
+
+// WithMaxSeedHits makes the mapper skip seeds with more than n hits.
+// Seeds in repeats hit all over the text, and we would spend most of
+// the time verifying their diagonals. By default, we use all the seeds.
+func WithMaxSeedHits(n int) MapperOption {
+	return func(cfg *mapperConfig) {
+		cfg.maxHits = n
+	}
+}
+
+// eachDiagonal sorts the diagonals and calls fn once for each of
+// them, even if several seeds put the read on it.
+func eachDiagonal(diags []int, fn func(d int)) {
+	sort.Ints(diags)
+
+	for i, d := range diags {
+		if i == 0 || d != diags[i-1] {
+			fn(d)
+		}
+	}
+}
+
+// FMIndexMapperFromTables returns a search function that maps reads to
+// x with seed-and-extend: exact seeds from the FM-index in tbls, which
+// must be the tables for x, verified on each of their diagonals with a
+// banded alignment. It reports the best hit at each position that
+// has at most edits edits, with the same callback as the approximative
+// searches, but it doesn't report each way of editing the read to get
+// to a position.
+func FMIndexMapperFromTables(
+	x string, tbls *FMIndexTables, opts ...MapperOption,
+) func(p string, edits int, cb func(i int, cigar string)) {
+	cfg := newMapperConfig(opts)
+	search := FMIndexExactFromTables(tbls)
+
+	return func(p string, edits int, cb func(i int, cigar string)) {
+		if edits < 0 {
+			return // no alignment has fewer than zero edits
+		}
+
+		offsets, seedLen := cfg.seeds(len(p), edits)
+		diags := []int{}
+
+		for _, offset := range offsets {
+			hits := []int{}
+
+			search(p[offset:offset+seedLen], func(i int) { hits = append(hits, i-offset) })
+
+			if cfg.maxHits <= 0 || len(hits) <= cfg.maxHits {
+				diags = append(diags, hits...)
+			}
+		}
+
+		// The bands overlap, so we can find the same occurrence from
+		// several diagonals, and with more edits from some of them,
+		// but we only report the best hit at each position.
+		verifier := NewVerifier()
+		hits := []ApproxHit{}
+
+		eachDiagonal(diags, func(d int) {
+			if hit, ok := verifier.verifyBand(x, p, d-edits, d+edits, edits, d); ok {
+				hits = append(hits, hit)
+			}
+		})
+
+		for _, hit := range BestPerPosition(hits) {
+			cb(hit.Pos, hit.Cigar)
+		}
+	}
+}
+
+// FMIndexMapperPreprocess preprocesses the string x and returns a
+// function that you can use to map reads to it with seed-and-extend.
+func FMIndexMapperPreprocess(x string, opts ...MapperOption) func(p string, edits int, cb func(i int, cigar string)) {
+	return FMIndexMapperFromTables(x, BuildFMIndexExactTables(x), opts...)
+}
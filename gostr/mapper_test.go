@@ -0,0 +1,137 @@
+package gostr_test
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mailund/gostr/gostr"
+	"github.com/mailund/gostr/testutils"
+)
+
+// mutate makes edits random edits to p.
+func mutate(p string, edits int, alpha string, rng *rand.Rand) string {
+	for e := 0; e < edits; e++ {
+		i := rng.Intn(len(p))
+		a := string(alpha[rng.Intn(len(alpha))])
+
+		switch rng.Intn(3) {
+		case 0:
+			p = p[:i] + a + p[i+1:]
+		case 1:
+			p = p[:i] + a + p[i:]
+		default:
+			if len(p) > 1 {
+				p = p[:i] + p[i+1:]
+			}
+		}
+	}
+
+	return p
+}
+
+func TestMapperExamples(t *testing.T) {
+	x := "ttttacgtacgtacttttttttgggcccaaatttt"
+	mapper := gostr.FMIndexMapperPreprocess(x)
+
+	hits := approxHits(t, x, "acgtacgtac", 0, mapper)
+	if expected := []gostr.ApproxHit{{Pos: 4, Cigar: "10M", Edits: 0}}; !reflect.DeepEqual(hits, expected) {
+		t.Errorf("Expected %v, got %v", expected, hits)
+	}
+
+	hits = approxHits(t, x, "gggccaaat", 1, mapper)
+	if expected := []gostr.ApproxHit{{Pos: 23, Cigar: "9M", Edits: 1}}; !reflect.DeepEqual(hits, expected) {
+		t.Errorf("Expected %v, got %v", expected, hits)
+	}
+
+	if hits := approxHits(t, x, "gggggggggg", 2, mapper); len(hits) != 0 {
+		t.Errorf("Expected no hits, got %v", hits)
+	}
+}
+
+// In a repeat, the seeds for all the copies are on nearby diagonals,
+// and the mapper must still find each copy.
+func TestMapperRepeats(t *testing.T) {
+	x := strings.Repeat("acg", 1000)
+	p := "acgacgacgacg"
+
+	expected := []gostr.ApproxHit{}
+	for i := 0; i+len(p) <= len(x); i += 3 {
+		expected = append(expected, gostr.ApproxHit{Pos: i, Cigar: "12M", Edits: 0})
+	}
+
+	exact := []gostr.ApproxHit{}
+
+	for _, hit := range approxHits(t, x, p, 3, gostr.FMIndexMapperPreprocess(x)) {
+		if hit.Edits == 0 {
+			exact = append(exact, hit)
+		}
+	}
+
+	if len(exact) != 997 || !reflect.DeepEqual(exact, expected) {
+		t.Errorf("Expected the %d exact hits %v, got %d: %v", len(expected), expected, len(exact), exact)
+	}
+}
+
+// With the pigeonhole seeds, the mapper finds reads with up to k
+// edits where they came from, and it only reports hits with at most k
+// edits.
+func TestMapperPigeonhole(t *testing.T) {
+	rng := testutils.NewRandomSeed(t)
+
+	for n := 0; n < 50; n++ {
+		x := testutils.RandomStringRange(50, 200, "acgt", rng)
+		k := rng.Intn(4)
+		pos := rng.Intn(len(x) - 40)
+		read := mutate(x[pos:pos+20+rng.Intn(20)], k, "acgt", rng)
+
+		tbls := gostr.BuildFMIndexExactTables(x)
+		hits := approxHits(t, x, read, k, gostr.FMIndexMapperFromTables(x, tbls))
+		found := false
+
+		for _, hit := range hits {
+			if hit.Edits > k {
+				t.Errorf("Mapped %q to %v in %q, with too many edits", read, hit, x)
+			}
+
+			found = found || (pos-2*k <= hit.Pos && hit.Pos <= pos+2*k)
+		}
+
+		if !found {
+			t.Errorf("Didn't map %q from %d in %q back to it, found %v", read, pos, x, hits)
+		}
+	}
+}
+
+// Long reads with many edits are out of reach of the backtracking
+// search, but the mapper finds them where they came from.
+func TestMapperLongReads(t *testing.T) {
+	rng := testutils.NewRandomSeed(t)
+	x := testutils.RandomStringN(50000, "acgt", rng)
+	mapper := gostr.FMIndexMapperPreprocess(x, gostr.WithSeedLength(20), gostr.WithMaxSeedHits(100))
+
+	for n := 0; n < 10; n++ {
+		pos := rng.Intn(len(x) - 2000)
+		read := mutate(x[pos:pos+1000+rng.Intn(1000)], 20, "acgt", rng)
+
+		found := false
+
+		mapper(read, 40, func(i int, cigar string) {
+			c, err := gostr.ParseCigar(cigar)
+			if err != nil || c.ReadLength() != len(read) {
+				t.Fatalf("Invalid cigar %q for a read of length %d", cigar, len(read))
+			}
+
+			if edits, _ := gostr.CountEdits(x, read, i, cigar); edits > 40 {
+				t.Errorf("Mapped the read to %d with %d edits", i, edits)
+			}
+
+			found = found || (pos-20 <= i && i <= pos+20)
+		})
+
+		if !found {
+			t.Errorf("Didn't map the read from %d back to it", pos)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+//go:build linux
+
+package gostr
+
+import (
+	"os"
+	"syscall"
+)
+
+// mmapFile maps the content of f into memory, read-only and shared,
+// so other processes that map the same file share the pages.
+func mmapFile(f *os.File) ([]byte, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	if info.Size() == 0 {
+		return []byte{}, nil // we cannot map an empty file
+	}
+
+	return syscall.Mmap(int(f.Fd()), 0, int(info.Size()), syscall.PROT_READ, syscall.MAP_SHARED)
+}
+
+// munmap releases memory we got from mmapFile.
+func munmap(data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	return syscall.Munmap(data)
+}
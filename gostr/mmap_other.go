@@ -0,0 +1,20 @@
+//go:build !linux
+
+package gostr
+
+import (
+	"io"
+	"os"
+)
+
+// mmapFile reads the content of f into memory. On this platform we do
+// not memory-map files, so the data is an ordinary heap allocation.
+func mmapFile(f *os.File) ([]byte, error) {
+	return io.ReadAll(f)
+}
+
+// munmap releases memory we got from mmapFile, which on this
+// platform is left to the garbage collector.
+func munmap([]byte) error {
+	return nil
+}
@@ -0,0 +1,131 @@
+package gostr
+
+// Myers' bit-parallel algorithm for approximate matching (Myers 1999,
+// with Hyyrö's formulation for patterns longer than a word). It
+// computes the same dynamic programming table as the verifier, with
+// the edit distance from p to the best substring of x that ends at
+// each position, but it doesn't store the values. Instead, it stores
+// the differences between adjacent rows in a column as bit-vectors,
+// one bit per row, and updates a whole column with a handful of word
+// operations. We split the column into blocks of 64 rows, so patterns
+// of length m take O(n⌈m/64⌉) time.
+
+// myersWordSize is the number of rows in a block.
+const myersWordSize = 64
+
+// myersBlock holds the vertical differences in a block of rows: a bit
+// in pv is set if the value goes up by one from the row above, and a
+// bit in mv if it goes down by one.
+type myersBlock struct {
+	pv, mv uint64
+}
+
+// advance moves the block to the next column, where eq has the bits
+// for the rows where p matches the letter in x, and hin is the
+// horizontal difference in the row above the block. It returns the
+// horizontal difference in the last row of the block, hbit.
+func (b *myersBlock) advance(eq uint64, hin int, hbit uint64) (hout int) {
+	pv, mv := b.pv, b.mv
+
+	xv := eq | mv
+	if hin < 0 {
+		eq |= 1
+	}
+
+	xh := (((eq & pv) + pv) ^ pv) | eq
+	ph := mv | ^(xh | pv)
+	mh := pv & xh
+
+	switch {
+	case ph&hbit != 0:
+		hout = 1
+	case mh&hbit != 0:
+		hout = -1
+	}
+
+	ph, mh = ph<<1, mh<<1
+
+	switch {
+	case hin < 0:
+		mh |= 1
+	case hin > 0:
+		ph |= 1
+	}
+
+	b.pv = mh | ^(xv | ph)
+	b.mv = ph & xv
+
+	return hout
+}
+
+// Myers finds the approximate occurrences of p in x with at most k
+// edits, using Myers' bit-parallel algorithm, in O(n⌈m/64⌉) time. It
+// reports where the occurrences end rather than where they start, and
+// the fewest edits for any occurrence that ends there, so x[:end] has
+// a suffix that aligns to p with edits edits. If you need the start
+// and the cigar, you can get them with a Verifier on the region that
+// ends at end.
+//
+// Parameters:
+//   - x: the string we search in.
+//   - p: the string we search for
+//   - k: the largest number of edits we allow
+//   - callback: a function called for each end position, with the
+//     number of edits
+func Myers(x, p string, k int, callback func(end, edits int)) {
+	m := len(p)
+	if k < 0 {
+		return
+	}
+
+	// With no letters from x, we have to insert all of p.
+	if m <= k {
+		callback(0, m)
+	}
+
+	if m == 0 {
+		for end := 1; end <= len(x); end++ {
+			callback(end, 0)
+		}
+
+		return
+	}
+
+	nblocks := (m + myersWordSize - 1) / myersWordSize
+	hbit := uint64(1) << ((m - 1) % myersWordSize) // the last row in the last block
+
+	// peq[a*nblocks+b] has the bits for the rows in block b where p
+	// has the letter a.
+	peq := make([]uint64, 256*nblocks)
+	for j := 0; j < m; j++ {
+		peq[int(p[j])*nblocks+j/myersWordSize] |= 1 << (j % myersWordSize)
+	}
+
+	// In the first column, row j has value j, so all the vertical
+	// differences are one.
+	blocks := make([]myersBlock, nblocks)
+	for b := range blocks {
+		blocks[b].pv = ^uint64(0)
+	}
+
+	edits := m
+
+	for i := 0; i < len(x); i++ {
+		eq := peq[int(x[i])*nblocks:]
+
+		// The first row is zero in all columns, since an occurrence can
+		// start anywhere, so there is no horizontal difference above
+		// the first block.
+		h := 0
+
+		for b := 0; b < nblocks-1; b++ {
+			h = blocks[b].advance(eq[b], h, 1<<(myersWordSize-1))
+		}
+
+		edits += blocks[nblocks-1].advance(eq[nblocks-1], h, hbit)
+
+		if edits <= k {
+			callback(i+1, edits)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package gostr_test
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/mailund/gostr/gostr"
+	"github.com/mailund/gostr/testutils"
+)
+
+func collectMyersHits(x, p string, k int) []string {
+	hits := []string{}
+
+	gostr.Myers(x, p, k, func(end, edits int) {
+		hits = append(hits, fmt.Sprintf("%d:%d", end, edits))
+	})
+	sort.Strings(hits)
+
+	return hits
+}
+
+// bruteEnds computes the fewest edits for an occurrence of p that ends
+// at each position in x, with the dynamic programming table, and
+// reports the ends with at most k edits.
+func bruteEnds(x, p string, k int) []string {
+	col := make([]int, len(p)+1)
+	for j := range col {
+		col[j] = j
+	}
+
+	hits := []string{}
+	if col[len(p)] <= k {
+		hits = append(hits, fmt.Sprintf("%d:%d", 0, col[len(p)]))
+	}
+
+	for i := 0; i < len(x); i++ {
+		diag := col[0]
+
+		for j := 1; j <= len(p); j++ {
+			cost := 1
+			if x[i] == p[j-1] {
+				cost = 0
+			}
+
+			best := diag + cost
+			if col[j-1]+1 < best {
+				best = col[j-1] + 1
+			}
+
+			if col[j]+1 < best {
+				best = col[j] + 1
+			}
+
+			diag, col[j] = col[j], best
+		}
+
+		if col[len(p)] <= k {
+			hits = append(hits, fmt.Sprintf("%d:%d", i+1, col[len(p)]))
+		}
+	}
+
+	sort.Strings(hits)
+
+	return hits
+}
+
+func TestMyers(t *testing.T) {
+	type args struct {
+		x, p string
+		k    int
+	}
+
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{"Exact", args{"acgtacgt", "acg", 0}, []string{"3:0", "7:0"}},
+		{"One edit", args{"acgttt", "acgt", 1}, []string{"3:1", "4:0", "5:1"}},
+		{"Negative", args{"aaaa", "a", -1}, []string{}},
+		{"Empty pattern", args{"ab", "", 0}, []string{"0:0", "1:0", "2:0"}},
+		{"Empty text", args{"", "ab", 2}, []string{"0:2"}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if hits := collectMyersHits(tt.args.x, tt.args.p, tt.args.k); !reflect.DeepEqual(hits, tt.want) {
+				t.Errorf("Myers() = %v, want %v", hits, tt.want)
+			}
+		})
+	}
+}
+
+func TestMyersRandom(t *testing.T) {
+	rng := testutils.NewRandomSeed(t)
+
+	// Patterns up to 200 letters span several blocks
+	for _, maxm := range []int{10, 70, 200} {
+		for n := 0; n < 20; n++ {
+			x := testutils.RandomStringRange(0, 300, "acg", rng)
+			p := testutils.RandomStringRange(0, maxm, "acg", rng)
+
+			// Plant a few occurrences of p, so we don't only find
+			// hits with many edits
+			if len(x) > len(p) {
+				i := rng.Intn(len(x) - len(p))
+				x = x[:i] + p + x[i+len(p):]
+			}
+
+			for _, k := range []int{0, 1, 3, len(p) / 3} {
+				if hits, want := collectMyersHits(x, p, k), bruteEnds(x, p, k); !reflect.DeepEqual(hits, want) {
+					t.Fatalf("Myers(%q, %q, %d) = %v, want %v", x, p, k, hits, want)
+				}
+			}
+		}
+	}
+}
+
+// The ends that Myers reports are the ends of the hits we get if we
+// verify the region that ends there.
+func TestMyersVerify(t *testing.T) {
+	rng := testutils.NewRandomSeed(t)
+	verifier := gostr.NewVerifier()
+
+	for n := 0; n < 20; n++ {
+		x := testutils.RandomStringRange(10, 100, "acgt", rng)
+		p := testutils.PickRandomSubstring(x, rng)
+		k := 2
+
+		gostr.Myers(x, p, k, func(end, edits int) {
+			hit, ok := verifier.Verify(x, p, 0, end, k)
+			if !ok || hit.Edits > edits {
+				t.Errorf("Myers found %q ending at %d in %q with %d edits, but verification found %v (%t)",
+					p, end, x, edits, hit, ok)
+			}
+		})
+	}
+}
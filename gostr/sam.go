@@ -0,0 +1,305 @@
+package gostr
+
+import (
+	"fmt"
+	"io"
+)
+
+// SAM flags we use
+const (
+	SAMReverse   = 0x10  // the reverse complement of the read aligns
+	SAMUnmapped  = 0x4   // the read didn't align
+	SAMSecondary = 0x100 // not the primary alignment of the read
+)
+
+// SAMHit is an alignment of a read to a record in a collection.
+type SAMHit struct {
+	QName string // the name of the read
+	Seq   string // the read, as it aligns to the record
+	Qual  string // the read's qualities, or empty if we don't have them
+
+	RName string // the name of the record we aligned to
+	Pos   int    // the zero-based offset in the record
+	Cigar string // the alignment, with M/I/D operations
+
+	// Reverse is true if the read aligns to the reverse strand. Pos
+	// and Cigar are always relative to the forward strand of the
+	// record, and as in SAM, Seq and Qual are the read oriented to the
+	// forward strand, i.e., the reverse complement of the read and its
+	// reversed qualities.
+	Reverse   bool
+	Secondary bool
+}
+
+// SAMWriter writes alignments to the records in a collection as SAM.
+// Like bufio.Writer, it remembers the first error it gets when writing
+// and returns it from all the later calls, so you can check errors
+// after writing all the alignments.
+type SAMWriter struct {
+	w       io.Writer
+	c       *SeqCollection
+	records map[string]int
+	err     error
+}
+
+// NewSAMWriter creates a writer for alignments to the records in c.
+func NewSAMWriter(w io.Writer, c *SeqCollection) *SAMWriter {
+	records := make(map[string]int, len(c.Names))
+	for i, name := range c.Names {
+		records[name] = i
+	}
+
+	return &SAMWriter{w: w, c: c, records: records}
+}
+
+func (sw *SAMWriter) printf(format string, args ...interface{}) {
+	if sw.err == nil {
+		_, sw.err = fmt.Fprintf(sw.w, format, args...)
+	}
+}
+
+// WriteHeader writes the header, with an @SQ line for each record.
+func (sw *SAMWriter) WriteHeader() error {
+	sw.printf("@HD\tVN:1.6\tSO:unsorted\n")
+
+	for i, name := range sw.c.Names {
+		sw.printf("@SQ\tSN:%s\tLN:%d\n", name, sw.c.recordEnd(i)-sw.c.Starts[i])
+	}
+
+	sw.printf("@PG\tID:gostr\tPN:gostr\n")
+
+	return sw.err
+}
+
+func samQual(qual string) string {
+	if qual == "" {
+		return "*"
+	}
+
+	return qual
+}
+
+// alignmentTags computes the edit distance (NM) and the mismatching
+// positions (MD) of an alignment of p against x.
+func alignmentTags(x, p string, pos int, cigar string) (nm int, md string, err error) {
+	view, err := NewAlignmentView(x, p, pos, cigar)
+	if err != nil {
+		return 0, "", err
+	}
+
+	return view.Edits, view.MD, nil
+}
+
+// samRecord is a hit together with its NM and MD tags.
+type samRecord struct {
+	hit *SAMHit
+	nm  int
+	md  string
+}
+
+// newRecord checks that hit is inside its record, and computes its
+// tags.
+func (sw *SAMWriter) newRecord(hit *SAMHit) (*samRecord, error) {
+	i, ok := sw.records[hit.RName]
+	if !ok {
+		return nil, fmt.Errorf("unknown record %q", hit.RName) //nolint:goerr113 // there is nothing to handle here
+	}
+
+	cigar, err := ParseCigar(hit.Cigar)
+	if err != nil {
+		return nil, err
+	}
+
+	if cigar.ReadLength() != len(hit.Seq) {
+		return nil, fmt.Errorf("cigar %s doesn't match a read of length %d", hit.Cigar, len(hit.Seq)) //nolint:goerr113 // there is nothing to handle here
+	}
+
+	record := sw.c.Seq[sw.c.Starts[i]:sw.c.recordEnd(i)]
+	if hit.Pos < 0 || hit.Pos+cigar.RefLength() > len(record) {
+		return nil, fmt.Errorf("alignment at %d is outside record %q", hit.Pos, hit.RName) //nolint:goerr113 // there is nothing to handle here
+	}
+
+	nm, md, err := alignmentTags(record, hit.Seq, hit.Pos, hit.Cigar)
+	if err != nil {
+		return nil, err
+	}
+
+	return &samRecord{hit: hit, nm: nm, md: md}, nil
+}
+
+func (sw *SAMWriter) writeRecord(rec *samRecord) {
+	hit, flag := rec.hit, 0
+
+	if hit.Reverse {
+		flag |= SAMReverse
+	}
+
+	if hit.Secondary {
+		flag |= SAMSecondary
+	}
+
+	sw.printf("%s\t%d\t%s\t%d\t255\t%s\t*\t0\t0\t%s\t%s\tNM:i:%d\tMD:Z:%s\n",
+		hit.QName, flag, hit.RName, hit.Pos+1, hit.Cigar, hit.Seq, samQual(hit.Qual), rec.nm, rec.md)
+}
+
+// WriteHit writes one alignment. It computes the NM and MD tags from the
+// record and the read, so the hit must be inside the record.
+func (sw *SAMWriter) WriteHit(hit *SAMHit) error {
+	rec, err := sw.newRecord(hit)
+	if err != nil {
+		return err
+	}
+
+	sw.writeRecord(rec)
+
+	return sw.err
+}
+
+// WriteUnmapped writes a record for a read that didn't align.
+func (sw *SAMWriter) WriteUnmapped(qname, seq, qual string) error {
+	sw.printf("%s\t%d\t*\t0\t0\t*\t*\t0\t0\t%s\t%s\n", qname, SAMUnmapped, seq, samQual(qual))
+	return sw.err
+}
+
+// WriteRead writes all the alignments of the read qname. The searches
+// can report the same alignment more than once, e.g., with different
+// but equivalent cigars, so we only write the first hit with a given
+// record, position, strand and cigar. The primary alignment is the
+// one with the fewest edits, or the leftmost of those if there is a
+// tie, and we write it first. The rest are secondary. The hits must
+// have their Seq and Qual set as for WriteHit, but we set QName and
+// Secondary. If there are no hits, we write the read, seq and qual,
+// as unmapped.
+func (sw *SAMWriter) WriteRead(qname, seq, qual string, hits []SAMHit) error {
+	type key struct {
+		rname   string
+		pos     int
+		reverse bool
+		cigar   string
+	}
+
+	seen := map[key]bool{}
+	recs := []*samRecord{}
+	primary := -1
+
+	for i := range hits {
+		hit := &hits[i]
+
+		k := key{rname: hit.RName, pos: hit.Pos, reverse: hit.Reverse, cigar: hit.Cigar}
+		if seen[k] {
+			continue
+		}
+
+		seen[k] = true
+
+		hit.QName, hit.Secondary = qname, true
+
+		rec, err := sw.newRecord(hit)
+		if err != nil {
+			return err
+		}
+
+		if primary < 0 || sw.before(rec, recs[primary]) {
+			primary = len(recs)
+		}
+
+		recs = append(recs, rec)
+	}
+
+	if len(recs) == 0 {
+		return sw.WriteUnmapped(qname, seq, qual)
+	}
+
+	recs[primary].hit.Secondary = false
+	sw.writeRecord(recs[primary])
+
+	for i, rec := range recs {
+		if i != primary {
+			sw.writeRecord(rec)
+		}
+	}
+
+	return sw.err
+}
+
+// before tells us if a should be the primary alignment rather than b:
+// it has fewer edits, or the same number of edits and comes first in
+// the collection.
+func (sw *SAMWriter) before(a, b *samRecord) bool {
+	if a.nm != b.nm {
+		return a.nm < b.nm
+	}
+
+	posA := sw.c.Starts[sw.records[a.hit.RName]] + a.hit.Pos
+	posB := sw.c.Starts[sw.records[b.hit.RName]] + b.hit.Pos
+
+	return posA < posB
+}
+
+// WriteApproxHits searches for a read with an approximative search
+// for the collection, e.g., from SeqCollection.FMIndexApproxFromTables,
+// and writes all the hits with WriteRead.
+func (sw *SAMWriter) WriteApproxHits(
+	search func(p string, edits int, cb func(name string, offset int, cigar string)),
+	edits int, qname, seq, qual string,
+) error {
+	hits := []SAMHit{}
+
+	search(seq, edits, func(name string, offset int, cigar string) {
+		hits = append(hits, SAMHit{Seq: seq, Qual: qual, RName: name, Pos: offset, Cigar: cigar})
+	})
+
+	return sw.WriteRead(qname, seq, qual, hits)
+}
+
+// OrientRead returns the read seq, with qualities qual, as it aligns
+// to the forward strand of the reference when it is a hit on strand,
+// which is how SAM wants it. For the reverse strand, that is the
+// reverse complement of the read and the reversed qualities, and you
+// get an error if seq isn't DNA.
+func OrientRead(seq, qual string, strand Strand) (oseq, oqual string, err error) {
+	if strand == Forward {
+		return seq, qual, nil
+	}
+
+	rc, err := ReverseComplement(seq)
+	if err != nil {
+		return "", "", err
+	}
+
+	return rc, reverseBytes(qual), nil
+}
+
+// WriteStrandedHits is WriteApproxHits for a stranded search, e.g., from
+// SeqCollection.FMIndexApproxStrandedFromTables. Hits on the reverse
+// strand get the reverse flag and the reverse complement of the read.
+func (sw *SAMWriter) WriteStrandedHits(
+	search func(p string, edits int, cb func(name string, offset int, strand Strand, cigar string)),
+	edits int, qname, seq, qual string,
+) error {
+	var (
+		hits []SAMHit
+		err  error
+	)
+
+	search(seq, edits, func(name string, offset int, strand Strand, cigar string) {
+		if err != nil {
+			return // we keep the first error
+		}
+
+		var oseq, oqual string
+		if oseq, oqual, err = OrientRead(seq, qual, strand); err == nil {
+			hits = append(hits, SAMHit{
+				Seq: oseq, Qual: oqual,
+				RName: name, Pos: offset, Cigar: cigar,
+				Reverse: strand == Reverse,
+			})
+		}
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return sw.WriteRead(qname, seq, qual, hits)
+}
@@ -0,0 +1,181 @@
+package gostr_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/mailund/gostr/gostr"
+)
+
+func newTestSAMWriter(t *testing.T) (*gostr.SAMWriter, *bytes.Buffer) {
+	t.Helper()
+
+	c, err := gostr.NewSeqCollection([]gostr.SeqRecord{
+		{Name: "chr1", Seq: "ACGTACGTAA"},
+		{Name: "chr2", Seq: "GGATTC"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	var buf bytes.Buffer
+
+	return gostr.NewSAMWriter(&buf, c), &buf
+}
+
+func TestSAMHeader(t *testing.T) {
+	sw, buf := newTestSAMWriter(t)
+
+	if err := sw.WriteHeader(); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	expected := "@HD\tVN:1.6\tSO:unsorted\n@SQ\tSN:chr1\tLN:10\n@SQ\tSN:chr2\tLN:6\n@PG\tID:gostr\tPN:gostr\n"
+	if buf.String() != expected {
+		t.Errorf("Got header %q, expected %q", buf.String(), expected)
+	}
+}
+
+func TestSAMHits(t *testing.T) {
+	tests := []struct {
+		name     string
+		hit      gostr.SAMHit
+		expected string
+	}{
+		{
+			"Exact",
+			gostr.SAMHit{QName: "r", Seq: "CGTA", Qual: "IIII", RName: "chr1", Pos: 1, Cigar: "4M"},
+			"r\t0\tchr1\t2\t255\t4M\t*\t0\t0\tCGTA\tIIII\tNM:i:0\tMD:Z:4",
+		},
+		{
+			"Mismatches",
+			gostr.SAMHit{QName: "r", Seq: "TCGA", RName: "chr1", Pos: 1, Cigar: "4M"},
+			"r\t0\tchr1\t2\t255\t4M\t*\t0\t0\tTCGA\t*\tNM:i:3\tMD:Z:0C0G0T1",
+		},
+		{
+			"Deletion",
+			gostr.SAMHit{QName: "r", Seq: "ACAC", RName: "chr1", Pos: 0, Cigar: "2M2D2M"},
+			"r\t0\tchr1\t1\t255\t2M2D2M\t*\t0\t0\tACAC\t*\tNM:i:2\tMD:Z:2^GT2",
+		},
+		{
+			"Deletion then mismatch",
+			gostr.SAMHit{QName: "r", Seq: "ACTC", RName: "chr1", Pos: 0, Cigar: "2M2D2M"},
+			"r\t0\tchr1\t1\t255\t2M2D2M\t*\t0\t0\tACTC\t*\tNM:i:3\tMD:Z:2^GT0A1",
+		},
+		{
+			"Insertion",
+			gostr.SAMHit{QName: "r", Seq: "GGTAT", RName: "chr2", Pos: 0, Cigar: "2M1I2M"},
+			"r\t0\tchr2\t1\t255\t2M1I2M\t*\t0\t0\tGGTAT\t*\tNM:i:1\tMD:Z:4",
+		},
+		{
+			"Soft clipped",
+			gostr.SAMHit{QName: "r", Seq: "TTCGTA", RName: "chr1", Pos: 1, Cigar: "2S4M"},
+			"r\t0\tchr1\t2\t255\t2S4M\t*\t0\t0\tTTCGTA\t*\tNM:i:0\tMD:Z:4",
+		},
+		{
+			"Sequence match and mismatch",
+			gostr.SAMHit{QName: "r", Seq: "CGTT", RName: "chr1", Pos: 1, Cigar: "3=1X"},
+			"r\t0\tchr1\t2\t255\t3=1X\t*\t0\t0\tCGTT\t*\tNM:i:1\tMD:Z:3A0",
+		},
+		{
+			"Reverse",
+			gostr.SAMHit{QName: "r", Seq: "GGATT", Qual: "EDCBA", RName: "chr2", Pos: 0, Cigar: "5M", Reverse: true},
+			"r\t16\tchr2\t1\t255\t5M\t*\t0\t0\tGGATT\tEDCBA\tNM:i:0\tMD:Z:5",
+		},
+		{
+			"Secondary reverse",
+			gostr.SAMHit{QName: "r", Seq: "GGATT", RName: "chr2", Pos: 0, Cigar: "5M", Reverse: true, Secondary: true},
+			"r\t272\tchr2\t1\t255\t5M\t*\t0\t0\tGGATT\t*\tNM:i:0\tMD:Z:5",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			sw, buf := newTestSAMWriter(t)
+
+			if err := sw.WriteHit(&tt.hit); err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+
+			if got := strings.TrimSuffix(buf.String(), "\n"); got != tt.expected {
+				t.Errorf("Got record\n%q, expected\n%q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSAMHitErrors(t *testing.T) {
+	sw, _ := newTestSAMWriter(t)
+
+	bad := []gostr.SAMHit{
+		{QName: "r", Seq: "ACGT", RName: "chr3", Pos: 0, Cigar: "4M"},
+		{QName: "r", Seq: "ACGT", RName: "chr2", Pos: 4, Cigar: "4M"},
+		{QName: "r", Seq: "ACGT", RName: "chr2", Pos: 0, Cigar: "4Q"},
+		{QName: "r", Seq: "ACGT", RName: "chr2", Pos: 0, Cigar: "3M"},
+	}
+
+	for _, hit := range bad {
+		hit := hit
+		if err := sw.WriteHit(&hit); err == nil {
+			t.Errorf("Expected an error for %v", hit)
+		}
+	}
+}
+
+func TestSAMApproxHits(t *testing.T) {
+	sw, buf := newTestSAMWriter(t)
+
+	c, _ := gostr.NewSeqCollection([]gostr.SeqRecord{
+		{Name: "chr1", Seq: "ACGTACGTAA"},
+		{Name: "chr2", Seq: "GGATTC"},
+	})
+	search := c.FMIndexApproxPreprocess()
+
+	if err := sw.WriteApproxHits(search, 0, "r1", "GTAC", "IIII"); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if err := sw.WriteApproxHits(search, 0, "r2", "TTTT", ""); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	expected := []string{
+		"r1\t0\tchr1\t3\t255\t4M\t*\t0\t0\tGTAC\tIIII\tNM:i:0\tMD:Z:4",
+		"r2\t4\t*\t0\t0\t*\t*\t0\t0\tTTTT\t*",
+	}
+
+	if len(lines) != len(expected) || lines[0] != expected[0] || lines[1] != expected[1] {
+		t.Errorf("Got records %q, expected %q", lines, expected)
+	}
+}
+
+func TestSAMPrimaryHit(t *testing.T) {
+	sw, buf := newTestSAMWriter(t)
+
+	// The exact hit comes last, and the first hit comes twice.
+	search := func(p string, edits int, cb func(name string, offset int, cigar string)) {
+		cb("chr1", 2, "4M")
+		cb("chr2", 1, "4M")
+		cb("chr1", 2, "4M")
+		cb("chr1", 6, "4M")
+	}
+
+	if err := sw.WriteApproxHits(search, 3, "r", "GTAA", ""); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	expected := []string{
+		"r\t0\tchr1\t7\t255\t4M\t*\t0\t0\tGTAA\t*\tNM:i:0\tMD:Z:4",
+		"r\t256\tchr1\t3\t255\t4M\t*\t0\t0\tGTAA\t*\tNM:i:1\tMD:Z:3C0",
+		"r\t256\tchr2\t2\t255\t4M\t*\t0\t0\tGTAA\t*\tNM:i:3\tMD:Z:1A0T0T0",
+	}
+
+	if strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Got records\n%q, expected\n%q", lines, expected)
+	}
+}
@@ -0,0 +1,65 @@
+package gostr
+
+// DefaultOTabSampling is the checkpoint interval we use for a
+// SampledOTab if you do not ask for anything else.
+const DefaultOTabSampling = 256
+
+// SampledOTab is a compact replacement for OTab. For each letter (except
+// the sentinel) it holds a bit vector that marks where the letter is in the
+// BWT, plus checkpoints with the rank at every K'th position. A rank query
+// is a checkpoint lookup followed by popcounts over the bits between the
+// checkpoint and the index.
+//
+// Where OTab uses a machine word per (letter, index) pair, SampledOTab
+// uses one bit per pair plus a 32-bit checkpoint for every K indices.
+type SampledOTab struct {
+	K    int
+	rows []*rankBitVector
+}
+
+// NewSampledOTab builds a sampled o-table from a BWT string over an alphabet
+// of size asize. The checkpoint interval is k rounded up to a multiple of 64.
+func NewSampledOTab(bwt []byte, asize, k int) *SampledOTab {
+	blockWords := (k + wordBits - 1) / wordBits
+	if blockWords < 1 {
+		blockWords = 1
+	}
+
+	// We index for all characters except $, so we have
+	// asize - 1 rows, just as in OTab.
+	rows := make([]*rankBitVector, asize-1)
+	for a := range rows {
+		rows[a] = newRankBitVector(len(bwt), blockWords)
+	}
+
+	for i, a := range bwt {
+		if a != Sentinel {
+			rows[a-1].set(i)
+		}
+	}
+
+	for _, row := range rows {
+		row.buildRanks()
+	}
+
+	return &SampledOTab{K: blockWords * wordBits, rows: rows}
+}
+
+// Rank How many times do we see letter a before index i
+// in the BWT string?
+func (otab *SampledOTab) Rank(a byte, i int) int {
+	return otab.rows[a-1].rank(i)
+}
+
+// Access returns the letter at index i in the BWT string. The letter's
+// row has its bit set at i, and if no row does, it is the sentinel, so
+// we probe one bit per letter instead of computing ranks.
+func (otab *SampledOTab) Access(i int) byte {
+	for a, row := range otab.rows {
+		if row.get(i) {
+			return byte(a + 1)
+		}
+	}
+
+	return Sentinel
+}
@@ -0,0 +1,72 @@
+package gostr
+
+// SampledSA is a suffix array where we only keep the entries for text
+// positions that are a multiple of the sampling rate, K. The remaining
+// entries can be recovered by LF-mapping in a BWT until we reach a
+// sampled position, which takes at most K-1 steps.
+type SampledSA struct {
+	K       int
+	marked  *rankBitVector // marks the SA indices where we have a sample
+	samples []int32        // the sampled values, in suffix array order
+}
+
+// NewSampledSA builds a sampled suffix array from a full suffix
+// array, keeping the entries whose values are multiples of k.
+func NewSampledSA(sa []int32, k int) *SampledSA {
+	if k < 1 {
+		k = 1
+	}
+
+	marked := newRankBitVector(len(sa), defaultBlockWords)
+	noSamples := 0
+
+	for i, j := range sa {
+		if int(j)%k == 0 {
+			marked.set(i)
+			noSamples++
+		}
+	}
+
+	marked.buildRanks()
+
+	samples := make([]int32, 0, noSamples)
+
+	for _, j := range sa {
+		if int(j)%k == 0 {
+			samples = append(samples, j)
+		}
+	}
+
+	return &SampledSA{K: k, marked: marked, samples: samples}
+}
+
+// Len returns the length of the (full) suffix array.
+func (ssa *SampledSA) Len() int {
+	return ssa.marked.length
+}
+
+// Sampled returns the suffix array value at index i and true if
+// it is sampled; otherwise it returns false.
+func (ssa *SampledSA) Sampled(i int) (int, bool) {
+	if !ssa.marked.get(i) {
+		return 0, false
+	}
+
+	return int(ssa.samples[ssa.marked.rank(i)]), true
+}
+
+// Lookup returns the suffix array value at index i. It uses lf, the
+// LF-mapping of the BWT the suffix array belongs to, to move backwards
+// in the text until it finds a sampled position.
+func (ssa *SampledSA) Lookup(i int, lf func(i int) int) int {
+	steps := 0
+
+	for {
+		if j, ok := ssa.Sampled(i); ok {
+			return j + steps
+		}
+
+		i = lf(i)
+		steps++
+	}
+}
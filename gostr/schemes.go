@@ -0,0 +1,361 @@
+package gostr
+
+import "fmt"
+
+// ApproxMode is the kind of errors an approximative search allows.
+type ApproxMode int
+
+// The approximative search modes.
+const (
+	EditDistance ApproxMode = iota // mismatches, insertions and deletions
+	Mismatches                     // only mismatches
+)
+
+// Search is one search in a search scheme. The pattern is split into
+// parts, and the search matches the parts in the order given by Order,
+// which must be a permutation of the parts where each part is next to
+// one of the parts before it. After matching the first t+1 parts in
+// Order, the number of errors must be between Lower[t] and Upper[t].
+type Search struct {
+	Order, Lower, Upper []int
+}
+
+// SearchScheme is a set of searches that, between them, find all the
+// occurrences of a pattern with up to some number of errors.
+//
+// The idea is that when we split a pattern into enough parts, some of
+// them must match with few errors, so a search that starts with those
+// parts can restrict the errors it considers early on, where the search
+// tree is widest, and only allow the remaining errors once the search
+// has narrowed down the interval.
+type SearchScheme struct {
+	Parts    int
+	Searches []Search
+}
+
+// MaxErrors returns the largest number of errors any of the searches
+// in the scheme allows.
+func (s *SearchScheme) MaxErrors() int {
+	k := 0
+
+	for _, search := range s.Searches {
+		if u := search.Upper[len(search.Upper)-1]; u > k {
+			k = u
+		}
+	}
+
+	return k
+}
+
+func (s *SearchScheme) validate() error {
+	if s.Parts < 1 || len(s.Searches) == 0 {
+		return fmt.Errorf("a search scheme needs at least one part and one search") //nolint:goerr113 // a programming error
+	}
+
+	for i, search := range s.Searches {
+		if len(search.Order) != s.Parts || len(search.Lower) != s.Parts || len(search.Upper) != s.Parts {
+			return fmt.Errorf("search %d doesn't have an entry for each part", i) //nolint:goerr113 // a programming error
+		}
+
+		lo, hi := search.Order[0], search.Order[0]
+
+		for _, part := range search.Order[1:] {
+			switch part {
+			case lo - 1:
+				lo = part
+			case hi + 1:
+				hi = part
+			default:
+				return fmt.Errorf("search %d doesn't extend the matched parts with part %d", i, part) //nolint:goerr113 // a programming error
+			}
+		}
+
+		if lo != 0 || hi != s.Parts-1 {
+			return fmt.Errorf("search %d doesn't cover all the parts", i) //nolint:goerr113 // a programming error
+		}
+	}
+
+	return nil
+}
+
+// PigeonholeScheme returns the scheme that splits the pattern into k+1
+// parts. At least one of them must match exactly, so there is a search
+// for each part that starts with it, with no errors, and then allows up
+// to k errors in the rest.
+func PigeonholeScheme(k int) *SearchScheme {
+	s := SearchScheme{Parts: k + 1}
+
+	for i := 0; i <= k; i++ {
+		search := Search{
+			Order: []int{i},
+			Lower: make([]int, k+1),
+			Upper: make([]int, k+1),
+		}
+
+		// Extend to the right first, then to the left
+		for j := i + 1; j <= k; j++ {
+			search.Order = append(search.Order, j)
+		}
+
+		for j := i - 1; j >= 0; j-- {
+			search.Order = append(search.Order, j)
+		}
+
+		for t := 1; t <= k; t++ {
+			search.Upper[t] = k
+		}
+
+		s.Searches = append(s.Searches, search)
+	}
+
+	return &s
+}
+
+// KucherovScheme returns a three-part scheme, of the kind Kucherov,
+// Salikhov and Tsur studied, for k = 1 or k = 2. It returns nil for
+// other k.
+func KucherovScheme(k int) *SearchScheme {
+	switch k {
+	case 1:
+		return &SearchScheme{Parts: 3, Searches: []Search{
+			{Order: []int{0, 1, 2}, Lower: []int{0, 0, 0}, Upper: []int{0, 1, 1}},
+			{Order: []int{2, 1, 0}, Lower: []int{0, 0, 0}, Upper: []int{0, 1, 1}},
+		}}
+	case 2: //nolint:gomnd // this is just the number of errors
+		return &SearchScheme{Parts: 3, Searches: []Search{
+			{Order: []int{0, 1, 2}, Lower: []int{0, 0, 0}, Upper: []int{0, 2, 2}},
+			{Order: []int{2, 1, 0}, Lower: []int{0, 0, 0}, Upper: []int{0, 1, 2}},
+			{Order: []int{1, 0, 2}, Lower: []int{0, 0, 1}, Upper: []int{0, 1, 2}},
+		}}
+	default:
+		return nil
+	}
+}
+
+// DefaultSearchScheme returns the scheme we use for k errors: a Kucherov
+// scheme if there is one and the pigeonhole scheme otherwise.
+func DefaultSearchScheme(k int) *SearchScheme {
+	if s := KucherovScheme(k); s != nil {
+		return s
+	}
+
+	return PigeonholeScheme(k)
+}
+
+// schemeSearch holds the state for searching for one pattern with one
+// search from a scheme. The pattern parts we have matched are p[lo:hi],
+// and we collect the operations for them in two stacks, one for each
+// direction, so the alignment is leftOps reversed followed by rightOps.
+type schemeSearch struct {
+	tbls   *FMIndexTables
+	mode   ApproxMode
+	p      []byte
+	bounds []int // part i is p[bounds[i]:bounds[i+1]]
+	search Search
+
+	leftOps, rightOps EditOps
+	counts            [][]int // a buffer for letter counts for each depth
+	report            func(iv biInterval, cigar string)
+}
+
+// partDone tells us if we have matched all of the part we are working on.
+func (ss *schemeSearch) partDone(t, lo, hi int, right bool) bool {
+	part := ss.search.Order[t]
+	if right {
+		return hi == ss.bounds[part+1]
+	}
+
+	return lo == ss.bounds[part]
+}
+
+// extendsRight tells us if part t in the search order extends the
+// matched parts to the right.
+func (ss *schemeSearch) extendsRight(t int) bool {
+	order := ss.search.Order
+	if t == 0 {
+		// We match the first part in the same direction as the second
+		return len(order) == 1 || order[1] > order[0]
+	}
+
+	lo := order[0]
+	for _, part := range order[:t] {
+		lo = smallest(lo, part)
+	}
+
+	return order[t] != lo-1
+}
+
+// startPart sets up the search for part t in the search order.
+func (ss *schemeSearch) startPart(t, lo, hi int, iv biInterval, errors int) {
+	if t == len(ss.search.Order) {
+		cigar := OpsToCigar(append(revOps(&ss.leftOps), ss.rightOps...))
+		ss.report(iv, cigar)
+
+		return
+	}
+
+	if t == 0 {
+		// Start at the edge of the first part we extend from
+		if part := ss.search.Order[0]; ss.extendsRight(0) {
+			lo, hi = ss.bounds[part], ss.bounds[part]
+		} else {
+			lo, hi = ss.bounds[part+1], ss.bounds[part+1]
+		}
+	}
+
+	ss.step(t, lo, hi, iv, errors, ss.extendsRight(t))
+}
+
+func (ss *schemeSearch) countsBuffer() []int {
+	depth := len(ss.leftOps) + len(ss.rightOps)
+	for len(ss.counts) <= depth {
+		ss.counts = append(ss.counts, make([]int, ss.tbls.Alpha.Size()))
+	}
+
+	return ss.counts[depth]
+}
+
+func (ss *schemeSearch) withOp(right bool, op ApproxEdit, fn func()) {
+	if right {
+		withOp(&ss.rightOps, op, fn)
+	} else {
+		withOp(&ss.leftOps, op, fn)
+	}
+}
+
+// step extends the match by one operation in part t.
+func (ss *schemeSearch) step(t, lo, hi int, iv biInterval, errors int, right bool) {
+	if ss.partDone(t, lo, hi, right) {
+		if errors >= ss.search.Lower[t] {
+			ss.startPart(t+1, lo, hi, iv, errors)
+		}
+
+		return
+	}
+
+	upper := ss.search.Upper[t]
+
+	// The pattern letter we match next and the interval we get from it
+	j, nextLo, nextHi := lo-1, lo-1, hi
+	if right {
+		j, nextLo, nextHi = hi, lo, hi+1
+	}
+
+	ss.tbls.biExtend(iv, !right, ss.countsBuffer(), func(a byte, next biInterval) {
+		cost := 0
+		if a != ss.p[j] {
+			cost = 1
+		}
+
+		if errors+cost <= upper {
+			ss.withOp(right, Match, func() { ss.step(t, nextLo, nextHi, next, errors+cost, right) })
+		}
+
+		// A deletion goes between the pattern letters we have matched
+		// and the next one, so there must be letters on both sides.
+		if ss.mode == EditDistance && errors < upper && lo < hi {
+			ss.withOp(right, Delete, func() { ss.step(t, lo, hi, next, errors+1, right) })
+		}
+	})
+
+	if ss.mode == EditDistance && errors < upper {
+		ss.withOp(right, Insert, func() { ss.step(t, nextLo, nextHi, iv, errors+1, right) })
+	}
+}
+
+// partBounds splits a pattern of length m into parts of (almost)
+// the same length.
+func partBounds(m, parts int) []int {
+	bounds := make([]int, parts+1)
+	for i := range bounds {
+		bounds[i] = i * m / parts
+	}
+
+	return bounds
+}
+
+// FMIndexSchemeFromTables returns a search function that uses a search
+// scheme on the bidirectional FM-index in the approximative tables. It
+// finds the occurrences with up to scheme.MaxErrors() errors. In the
+// EditDistance mode, it reports the same positions and cigars as the
+// search from FMIndexApproxFromTables, and in the Mismatches mode, the
+// same positions as the search from FMIndexHammingFromTables, where
+// letters that aren't in the alphabet are mismatches.
+func FMIndexSchemeFromTables(
+	tbls *FMIndexTables, mode ApproxMode, scheme *SearchScheme,
+) (func(p string, cb func(i int, cigar string)), error) {
+	if err := scheme.validate(); err != nil {
+		return nil, err
+	}
+
+	return func(p string, cb func(i int, cigar string)) {
+		pb, err := tbls.Alpha.MapToBytes(p)
+
+		switch {
+		case mode == Mismatches:
+			// As in FMIndexHammingFromTables, a letter that isn't in
+			// the alphabet is a mismatch wherever we put it.
+			pb = tbls.Alpha.mapWithMismatches(p)
+		case err != nil:
+			return // p doesn't fit the alphabet, so we can't match
+		}
+
+		// Different searches can find the same alignment, so we only
+		// report the first. The same cigar and interval means the same
+		// alignments to the same text strings, so we have already
+		// reported the whole interval if we have seen the pair before.
+		type hits struct {
+			cigar string
+			left  int
+		}
+
+		seen := map[hits]bool{}
+		ss := schemeSearch{
+			tbls:   tbls,
+			mode:   mode,
+			p:      pb,
+			bounds: partBounds(len(pb), scheme.Parts),
+			report: func(iv biInterval, cigar string) {
+				key := hits{cigar, iv.left}
+				if seen[key] {
+					return
+				}
+
+				seen[key] = true
+
+				for j := iv.left; j < iv.left+iv.size; j++ {
+					cb(tbls.saLookup(j), cigar)
+				}
+			},
+		}
+
+		for _, search := range scheme.Searches {
+			ss.search = search
+			ss.startPart(0, 0, 0, tbls.fullBiInterval(), 0)
+		}
+	}, nil
+}
+
+// FMIndexBidirFromTables returns an approximative search function that
+// uses the default search scheme for the number of errors. In the
+// EditDistance mode, it reports the same positions and cigars as the
+// search from FMIndexApproxFromTables.
+func FMIndexBidirFromTables(tbls *FMIndexTables, mode ApproxMode) func(p string, edits int, cb func(i int, cigar string)) {
+	return func(p string, edits int, cb func(i int, cigar string)) {
+		if edits < 0 {
+			return // no alignment has fewer than zero edits
+		}
+
+		search, err := FMIndexSchemeFromTables(tbls, mode, DefaultSearchScheme(edits))
+		checkError(err) // our own schemes are valid
+
+		search(p, cb)
+	}
+}
+
+// FMIndexBidirPreprocess preprocesses the string x and returns a
+// function that you can use to efficiently search in x with search
+// schemes.
+func FMIndexBidirPreprocess(x string, mode ApproxMode) func(p string, edits int, cb func(i int, cigar string)) {
+	return FMIndexBidirFromTables(BuildFMIndexApproxTables(x), mode)
+}
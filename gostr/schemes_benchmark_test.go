@@ -0,0 +1,46 @@
+package gostr_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mailund/gostr/gostr"
+	"github.com/mailund/gostr/testutils"
+)
+
+func Benchmark_ApproxSearchSchemes(b *testing.B) {
+	const m = 30
+
+	rng := testutils.NewRandomSeed(b)
+	strings := map[string]string{
+		"Random":    testutils.RandomStringN(100000, "acgt", rng),
+		"Fibonacci": testutils.FibonacciString(24),
+	}
+
+	for name, x := range strings {
+		tbls := gostr.BuildFMIndexApproxTables(x)
+		searches := map[string]func(p string, edits int, cb func(i int, cigar string)){
+			"Recursive": gostr.FMIndexApproxFromTables(tbls),
+			"Bidir":     gostr.FMIndexBidirFromTables(tbls, gostr.EditDistance),
+		}
+
+		patterns := make([]string, 100)
+		for i := range patterns {
+			j := rng.Intn(len(x) - m)
+			patterns[i] = x[j : j+m]
+		}
+
+		for _, k := range []int{1, 2, 3} {
+			for algo, search := range searches {
+				search := search
+				k := k
+
+				b.Run(fmt.Sprintf("%s:%s:k=%d", name, algo, k), func(b *testing.B) {
+					for i := 0; i < b.N; i++ {
+						search(patterns[i%len(patterns)], k, func(int, string) {})
+					}
+				})
+			}
+		}
+	}
+}
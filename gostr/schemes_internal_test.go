@@ -0,0 +1,124 @@
+package gostr
+
+import (
+	"strconv"
+	"testing"
+)
+
+// errorDistributions calls fn with all the ways we can distribute up to
+// k errors over n elements.
+func errorDistributions(n, k int, fn func(dist []int)) {
+	dist := make([]int, n)
+
+	var rec func(i, left int)
+
+	rec = func(i, left int) {
+		if i == n {
+			fn(dist)
+			return
+		}
+
+		for e := 0; e <= left; e++ {
+			dist[i] = e
+			rec(i+1, left-e)
+		}
+
+		dist[i] = 0
+	}
+
+	rec(0, k)
+}
+
+// accepts tells us if search accepts an alignment with core errors in the
+// parts and gap errors in the deletions between neighbouring parts. With
+// deletions at part boundaries, the errors after matching some parts are
+// the errors in those parts plus the gaps between them.
+func (search *Search) accepts(core, gaps []int) bool {
+	lo, hi := search.Order[0], search.Order[0]
+
+	for t, part := range search.Order {
+		if part < lo {
+			lo = part
+		}
+
+		if part > hi {
+			hi = part
+		}
+
+		errors := 0
+		for i := lo; i <= hi; i++ {
+			errors += core[i]
+		}
+
+		for i := lo; i < hi; i++ {
+			errors += gaps[i]
+		}
+
+		if errors < search.Lower[t] || errors > search.Upper[t] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestSearchSchemesComplete(t *testing.T) {
+	schemes := map[string]*SearchScheme{
+		"Kucherov-1": KucherovScheme(1),
+		"Kucherov-2": KucherovScheme(2),
+	}
+
+	for k := 0; k < 5; k++ {
+		schemes["Pigeonhole-"+strconv.Itoa(k)] = PigeonholeScheme(k)
+	}
+
+	for name, scheme := range schemes {
+		if err := scheme.validate(); err != nil {
+			t.Errorf("Scheme %s is invalid: %s", name, err)
+			continue
+		}
+
+		p, k := scheme.Parts, scheme.MaxErrors()
+
+		errorDistributions(2*p-1, k, func(dist []int) {
+			for i := range scheme.Searches {
+				if scheme.Searches[i].accepts(dist[:p], dist[p:]) {
+					return
+				}
+			}
+
+			t.Errorf("Scheme %s doesn't find alignments with errors %v", name, dist)
+		})
+	}
+}
+
+func TestSearchSchemeValidate(t *testing.T) {
+	invalid := []SearchScheme{
+		{},
+		{Parts: 2},
+		{Parts: 2, Searches: []Search{{Order: []int{0}, Lower: []int{0}, Upper: []int{0}}}},
+		{Parts: 3, Searches: []Search{{Order: []int{0, 2, 1}, Lower: []int{0, 0, 0}, Upper: []int{0, 1, 1}}}},
+		{Parts: 2, Searches: []Search{{Order: []int{1, 2}, Lower: []int{0, 0}, Upper: []int{0, 1}}}},
+	}
+
+	for i := range invalid {
+		if err := invalid[i].validate(); err == nil {
+			t.Errorf("Expected scheme %v to be invalid", invalid[i])
+		}
+	}
+
+	if KucherovScheme(3) != nil {
+		t.Errorf("We don't have a Kucherov scheme for three errors")
+	}
+}
+
+func Test_partBounds(t *testing.T) {
+	bounds := partBounds(10, 3)
+	if bounds[0] != 0 || bounds[3] != 10 || bounds[1] != 3 || bounds[2] != 6 {
+		t.Errorf("Unexpected bounds %v", bounds)
+	}
+
+	if bounds := partBounds(1, 3); bounds[3] != 1 {
+		t.Errorf("Unexpected bounds %v", bounds)
+	}
+}
@@ -0,0 +1,159 @@
+package gostr_test
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/mailund/gostr/gostr"
+	"github.com/mailund/gostr/testutils"
+)
+
+func collectApproxHits(search func(p string, edits int, cb func(i int, cigar string)), p string, edits int) []string {
+	hits := []string{}
+
+	search(p, edits, func(i int, cigar string) {
+		hits = append(hits, fmt.Sprintf("%d:%s", i, cigar))
+	})
+	sort.Strings(hits)
+
+	return hits
+}
+
+// naiveMismatches finds the occurrences of p in x with up to k mismatches.
+func naiveMismatches(x, p string, k int) []string {
+	hits := []string{}
+	cigar := ""
+
+	if len(p) > 0 {
+		cigar = fmt.Sprintf("%dM", len(p))
+	}
+
+	for i := 0; i+len(p) <= len(x); i++ {
+		mismatches := 0
+
+		for j := 0; j < len(p); j++ {
+			if x[i+j] != p[j] {
+				mismatches++
+			}
+		}
+
+		if mismatches <= k {
+			hits = append(hits, fmt.Sprintf("%d:%s", i, cigar))
+		}
+	}
+
+	sort.Strings(hits)
+
+	return hits
+}
+
+func TestBidirEditDistance(t *testing.T) {
+	rng := testutils.NewRandomSeed(t)
+
+	check := func(x string, opts ...gostr.FMIndexOption) {
+		tbls := gostr.BuildFMIndexApproxTables(x, opts...)
+		recursive := gostr.FMIndexApproxFromTables(tbls)
+		bidir := gostr.FMIndexBidirFromTables(tbls, gostr.EditDistance)
+
+		for j := 0; j < 5; j++ {
+			p := testutils.RandomStringRange(0, 10, "acg", rng)
+			if len(x) > 1 && rng.Intn(2) == 0 {
+				p = testutils.PickRandomSubstring(x, rng)
+			}
+
+			edits := rng.Intn(4)
+
+			expected := collectApproxHits(recursive, p, edits)
+			if hits := collectApproxHits(bidir, p, edits); !reflect.DeepEqual(hits, expected) {
+				t.Fatalf("Searching for %q in %q with %d edits gave\n%v, expected\n%v", p, x, edits, hits, expected)
+			}
+		}
+	}
+
+	for n := 0; n < 100; n++ {
+		check(testutils.RandomStringRange(1, 30, "acg", rng))
+	}
+
+	for n := 1; n < 8; n++ {
+		check(testutils.FibonacciString(n))
+	}
+
+	x := testutils.RandomStringN(50, "acg", rng)
+	check(x, gostr.WithSASampling(4))
+	check(x, gostr.WithWaveletOTab())
+	check(x, gostr.WithOTabSampling(8))
+}
+
+func TestBidirMismatches(t *testing.T) {
+	rng := testutils.NewRandomSeed(t)
+
+	for n := 0; n < 100; n++ {
+		x := testutils.RandomStringRange(1, 50, "acgt", rng)
+		search := gostr.FMIndexBidirPreprocess(x, gostr.Mismatches)
+
+		for j := 0; j < 5; j++ {
+			p := testutils.RandomStringRange(0, 10, "acgt", rng)
+			edits := rng.Intn(5)
+
+			expected := naiveMismatches(x, p, edits)
+			if hits := collectApproxHits(search, p, edits); !reflect.DeepEqual(hits, expected) {
+				t.Fatalf("Searching for %q in %q with %d mismatches gave\n%v, expected\n%v", p, x, edits, hits, expected)
+			}
+		}
+	}
+}
+
+func TestSchemeSearch(t *testing.T) {
+	x := "mississippi"
+	tbls := gostr.BuildFMIndexApproxTables(x)
+
+	// A valid scheme we write ourselves: the pigeonhole scheme for two
+	// errors, but with the searches in the opposite direction.
+	scheme := &gostr.SearchScheme{Parts: 3, Searches: []gostr.Search{
+		{Order: []int{0, 1, 2}, Lower: []int{0, 0, 0}, Upper: []int{0, 2, 2}},
+		{Order: []int{1, 0, 2}, Lower: []int{0, 0, 0}, Upper: []int{0, 2, 2}},
+		{Order: []int{2, 1, 0}, Lower: []int{0, 0, 0}, Upper: []int{0, 2, 2}},
+	}}
+
+	if scheme.MaxErrors() != 2 {
+		t.Errorf("Expected the scheme to allow two errors, not %d", scheme.MaxErrors())
+	}
+
+	search, err := gostr.FMIndexSchemeFromTables(tbls, gostr.EditDistance, scheme)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	hits := []string{}
+	search("ssix", func(i int, cigar string) { hits = append(hits, fmt.Sprintf("%d:%s", i, cigar)) })
+	sort.Strings(hits)
+
+	expected := collectApproxHits(gostr.FMIndexApproxFromTables(tbls), "ssix", 2)
+	if !reflect.DeepEqual(hits, expected) {
+		t.Errorf("Got hits %v, expected %v", hits, expected)
+	}
+
+	scheme.Searches[1].Order = []int{0, 2, 1}
+	if _, err := gostr.FMIndexSchemeFromTables(tbls, gostr.EditDistance, scheme); err == nil {
+		t.Errorf("Expected an error for an invalid scheme")
+	}
+
+	// Letters that are not in the alphabet, and negative edits, give no hits
+	bidir := gostr.FMIndexBidirFromTables(tbls, gostr.EditDistance)
+	if hits := collectApproxHits(bidir, "sxs", 1); len(hits) != 0 {
+		t.Errorf("Expected no hits, got %v", hits)
+	}
+
+	if hits := collectApproxHits(bidir, "ssi", -1); len(hits) != 0 {
+		t.Errorf("Expected no hits, got %v", hits)
+	}
+
+	// but with only mismatches, such letters are mismatches, as in the
+	// Hamming searches
+	bidir = gostr.FMIndexBidirFromTables(tbls, gostr.Mismatches)
+	if hits, expected := collectApproxHits(bidir, "sxs", 1), []string{"3:3M"}; !reflect.DeepEqual(hits, expected) {
+		t.Errorf("Got hits %v, expected %v", hits, expected)
+	}
+}
@@ -0,0 +1,178 @@
+package gostr
+
+// Strand is the strand of DNA a hit is on.
+type Strand int
+
+// The strands. A hit on the Reverse strand is a hit for the reverse
+// complement of the pattern.
+const (
+	Forward Strand = iota
+	Reverse
+)
+
+// String returns "+" for the forward strand and "-" for the reverse.
+func (s Strand) String() string {
+	if s == Reverse {
+		return "-"
+	}
+
+	return "+"
+}
+
+// complements maps DNA letters, including the IUPAC ambiguity codes,
+// to their complements, and all other bytes to zero, so we can tell
+// when a string isn't DNA.
+var complements = func() (table [256]byte) { //nolint:gochecknoglobals // a constant table we compute at startup
+	for _, pair := range []string{
+		"AT", "CG", "RY", "KM", "BV", "DH", "SS", "WW", "NN",
+		"at", "cg", "ry", "km", "bv", "dh", "ss", "ww", "nn",
+	} {
+		table[pair[0]], table[pair[1]] = pair[1], pair[0]
+	}
+
+	return table
+}()
+
+// ReverseComplement returns the reverse complement of a DNA string,
+// where the letters are nucleotides or IUPAC codes, in upper or lower
+// case. If x has a letter that isn't, you get a NotDNA error.
+func ReverseComplement(x string) (string, error) {
+	b := make([]byte, len(x))
+
+	for i := 0; i < len(x); i++ {
+		if b[len(x)-i-1] = complements[x[i]]; b[len(x)-i-1] == 0 {
+			return "", NewNotDNA(x[i])
+		}
+	}
+
+	return string(b), nil
+}
+
+// nonDNA returns a letter in the alphabet, other than the sentinel and
+// the letters in ignore, that isn't a nucleotide or IUPAC code, if
+// there is one.
+func (alpha *Alphabet) nonDNA(ignore []byte) (byte, bool) {
+	skip := [256]bool{Sentinel: true}
+	for _, a := range ignore {
+		skip[a] = true
+	}
+
+	for i := 0; i < alpha.Size(); i++ {
+		if a := alpha._revmap[i]; !skip[a] && complements[a] == 0 {
+			return a, true
+		}
+	}
+
+	return 0, false
+}
+
+// IsDNA tells us if the letters in the alphabet, except the sentinel
+// and the letters in ignore, are nucleotides or IUPAC codes, so we can
+// complement them. Use ignore for separators, like the Sep in a
+// SeqCollection.
+func (alpha *Alphabet) IsDNA(ignore ...byte) bool {
+	_, found := alpha.nonDNA(ignore)
+	return !found
+}
+
+// checkDNA gives us a NotDNA error if alpha, without the letters in
+// ignore, isn't DNA.
+func checkDNA(alpha *Alphabet, ignore ...byte) error {
+	if a, found := alpha.nonDNA(ignore); found {
+		return NewNotDNA(a)
+	}
+
+	return nil
+}
+
+// SearchStrands calls search with p on the forward strand and with the
+// reverse complement of p on the reverse strand. If p is its own reverse
+// complement, the two searches would give the same hits, so then we
+// only search the forward strand. If p isn't DNA, it has no reverse
+// complement, and we also only search the forward strand. In a DNA
+// text, such a p has no hits anyway.
+func SearchStrands(p string, search func(p string, strand Strand)) {
+	search(p, Forward)
+
+	if rc, err := ReverseComplement(p); err == nil && rc != p {
+		search(rc, Reverse)
+	}
+}
+
+// FMIndexExactStrandedFromTables returns a search function that searches
+// for a DNA pattern on both strands. Hits on the reverse strand are the
+// positions where the reverse complement of the pattern occurs. The
+// text must be DNA, or you get a NotDNA error.
+func FMIndexExactStrandedFromTables(tbls *FMIndexTables) (func(p string, cb func(i int, strand Strand)), error) {
+	if err := checkDNA(tbls.Alpha); err != nil {
+		return nil, err
+	}
+
+	search := FMIndexExactFromTables(tbls)
+
+	return func(p string, cb func(i int, strand Strand)) {
+		SearchStrands(p, func(p string, strand Strand) {
+			search(p, func(i int) { cb(i, strand) })
+		})
+	}, nil
+}
+
+// FMIndexApproxStrandedFromTables returns a search function that searches
+// for a DNA pattern on both strands. Hits on the reverse strand are
+// alignments of the reverse complement of the pattern, so the position
+// and the cigar are always relative to the forward reference, as in SAM.
+// The text must be DNA, or you get a NotDNA error.
+func FMIndexApproxStrandedFromTables(
+	tbls *FMIndexTables,
+) (func(p string, edits int, cb func(i int, strand Strand, cigar string)), error) {
+	if err := checkDNA(tbls.Alpha); err != nil {
+		return nil, err
+	}
+
+	search := FMIndexApproxFromTables(tbls)
+
+	return func(p string, edits int, cb func(i int, strand Strand, cigar string)) {
+		SearchStrands(p, func(p string, strand Strand) {
+			search(p, edits, func(i int, cigar string) { cb(i, strand, cigar) })
+		})
+	}, nil
+}
+
+// FMIndexExactStrandedFromTables is the collection version of the
+// stranded exact search; it reports the record and offset of each hit.
+// The records must be DNA, or you get a NotDNA error.
+func (c *SeqCollection) FMIndexExactStrandedFromTables(
+	tbls *FMIndexTables,
+) (func(p string, cb func(name string, offset int, strand Strand)), error) {
+	if err := checkDNA(tbls.Alpha, c.Sep); err != nil {
+		return nil, err
+	}
+
+	search := c.FMIndexExactFromTables(tbls)
+
+	return func(p string, cb func(name string, offset int, strand Strand)) {
+		SearchStrands(p, func(p string, strand Strand) {
+			search(p, func(name string, offset int) { cb(name, offset, strand) })
+		})
+	}, nil
+}
+
+// FMIndexApproxStrandedFromTables is the collection version of the
+// stranded approximative search; it reports the record and offset of
+// each hit together with the strand and the cigar. The records must be
+// DNA, or you get a NotDNA error.
+func (c *SeqCollection) FMIndexApproxStrandedFromTables(
+	tbls *FMIndexTables,
+) (func(p string, edits int, cb func(name string, offset int, strand Strand, cigar string)), error) {
+	if err := checkDNA(tbls.Alpha, c.Sep); err != nil {
+		return nil, err
+	}
+
+	search := c.FMIndexApproxFromTables(tbls)
+
+	return func(p string, edits int, cb func(name string, offset int, strand Strand, cigar string)) {
+		SearchStrands(p, func(p string, strand Strand) {
+			search(p, edits, func(name string, offset int, cigar string) { cb(name, offset, strand, cigar) })
+		})
+	}, nil
+}
@@ -0,0 +1,251 @@
+package gostr_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/mailund/gostr/gostr"
+	"github.com/mailund/gostr/testutils"
+)
+
+func TestStrandString(t *testing.T) {
+	if gostr.Forward.String() != "+" || gostr.Reverse.String() != "-" {
+		t.Errorf("Unexpected strand strings %s and %s", gostr.Forward, gostr.Reverse)
+	}
+}
+
+func TestReverseComplement(t *testing.T) {
+	tests := []struct {
+		x, expected string
+	}{
+		{"", ""},
+		{"ACGT", "ACGT"},
+		{"AACG", "CGTT"},
+		{"acgtn", "nacgt"},
+		{"RYKMBVDHSWN", "NWSDHBVKMRY"},
+	}
+
+	for _, tt := range tests {
+		if rc, err := gostr.ReverseComplement(tt.x); err != nil || rc != tt.expected {
+			t.Errorf("The reverse complement of %q is %q (%v), expected %q", tt.x, rc, err, tt.expected)
+		}
+	}
+
+	if _, err := gostr.ReverseComplement("AC-x"); !errors.Is(err, gostr.NewNotDNA('-')) {
+		t.Errorf("Expected a NotDNA error, got %v", err)
+	} else if err.Error() != "byte '-' is not a nucleotide or IUPAC code" {
+		t.Errorf("Unexpected error message: %s", err)
+	}
+}
+
+func TestIsDNA(t *testing.T) {
+	if !gostr.NewAlphabet("ACGTN").IsDNA() {
+		t.Error("ACGTN should be DNA")
+	}
+
+	if gostr.NewAlphabet("ACGU").IsDNA() {
+		t.Error("ACGU should not be DNA")
+	}
+
+	if gostr.NewAlphabet("AC#GT").IsDNA() {
+		t.Error("AC#GT should not be DNA")
+	}
+
+	if !gostr.NewAlphabet("AC#GT").IsDNA('#') {
+		t.Error("AC#GT should be DNA when we ignore #")
+	}
+}
+
+func TestOrientRead(t *testing.T) {
+	if seq, qual, err := gostr.OrientRead("AACG", "ABCD", gostr.Forward); err != nil || seq != "AACG" || qual != "ABCD" {
+		t.Errorf("Unexpected forward read %q %q (%v)", seq, qual, err)
+	}
+
+	if seq, qual, err := gostr.OrientRead("AACG", "ABCD", gostr.Reverse); err != nil || seq != "CGTT" || qual != "DCBA" {
+		t.Errorf("Unexpected reverse read %q %q (%v)", seq, qual, err)
+	}
+
+	if _, _, err := gostr.OrientRead("AAXG", "ABCD", gostr.Reverse); !errors.Is(err, gostr.NewNotDNA('X')) {
+		t.Errorf("Expected a NotDNA error, got %v", err)
+	}
+}
+
+func TestStrandedNotDNA(t *testing.T) {
+	tbls := gostr.BuildFMIndexApproxTables("ACGUACGU")
+
+	if _, err := gostr.FMIndexExactStrandedFromTables(tbls); !errors.Is(err, gostr.NewNotDNA('U')) {
+		t.Errorf("Expected a NotDNA error, got %v", err)
+	}
+
+	if _, err := gostr.FMIndexApproxStrandedFromTables(tbls); !errors.Is(err, gostr.NewNotDNA('U')) {
+		t.Errorf("Expected a NotDNA error, got %v", err)
+	}
+
+	c, err := gostr.NewSeqCollection([]gostr.SeqRecord{
+		{Name: "chr1", Seq: "ACGT"},
+		{Name: "chr2", Seq: "ACGX"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	tbls = gostr.BuildFMIndexApproxTables(c.Seq)
+
+	if _, err := c.FMIndexExactStrandedFromTables(tbls); !errors.Is(err, gostr.NewNotDNA('X')) {
+		t.Errorf("Expected a NotDNA error, got %v", err)
+	}
+
+	if _, err := c.FMIndexApproxStrandedFromTables(tbls); !errors.Is(err, gostr.NewNotDNA('X')) {
+		t.Errorf("Expected a NotDNA error, got %v", err)
+	}
+}
+
+// reverseComplement is ReverseComplement for tests where we know we
+// have DNA.
+func reverseComplement(t *testing.T, x string) string {
+	t.Helper()
+
+	rc, err := gostr.ReverseComplement(x)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	return rc
+}
+
+func TestFMIndexExactStranded(t *testing.T) {
+	rng := testutils.NewRandomSeed(t)
+
+	for n := 0; n < 20; n++ {
+		x := testutils.RandomStringRange(1, 200, "ACGT", rng)
+		search, err := gostr.FMIndexExactStrandedFromTables(gostr.BuildFMIndexExactTables(x))
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		for j := 0; j < 10; j++ {
+			p := testutils.RandomStringRange(1, 5, "ACGT", rng)
+			rc := reverseComplement(t, p)
+
+			expected := []string{}
+			gostr.Naive(x, p, func(i int) { expected = append(expected, fmt.Sprintf("%d+", i)) })
+
+			if rc != p {
+				gostr.Naive(x, rc, func(i int) { expected = append(expected, fmt.Sprintf("%d-", i)) })
+			}
+
+			hits := []string{}
+			search(p, func(i int, strand gostr.Strand) { hits = append(hits, fmt.Sprintf("%d%s", i, strand)) })
+
+			sort.Strings(expected)
+			sort.Strings(hits)
+
+			if !reflect.DeepEqual(hits, expected) {
+				t.Fatalf("Searching for %s in %s gave %v, expected %v", p, x, hits, expected)
+			}
+		}
+	}
+}
+
+func TestFMIndexApproxStranded(t *testing.T) {
+	rng := testutils.NewRandomSeed(t)
+
+	for n := 0; n < 20; n++ {
+		x := testutils.RandomStringRange(10, 100, "ACGT", rng)
+		search, err := gostr.FMIndexApproxStrandedFromTables(gostr.BuildFMIndexApproxTables(x))
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		for j := 0; j < 5; j++ {
+			p := testutils.RandomStringRange(3, 8, "ACGT", rng)
+			edits := rng.Intn(3)
+			reverse := 0
+
+			search(p, edits, func(i int, strand gostr.Strand, cigar string) {
+				// The cigar is relative to the forward strand, so for
+				// reverse hits it aligns the reverse complement
+				q := p
+				if strand == gostr.Reverse {
+					q = reverseComplement(t, p)
+					reverse++
+				}
+
+				if n, err := gostr.CountEdits(x, q, i, cigar); err != nil || n > edits {
+					t.Fatalf("Hit %d%s %s for %s in %s has %d edits (%v)", i, strand, cigar, p, x, n, err)
+				}
+			})
+
+			expected := 0
+			if rc := reverseComplement(t, p); rc != p {
+				gostr.FMIndexApproxPreprocess(x)(rc, edits, func(int, string) { expected++ })
+			}
+
+			if reverse != expected {
+				t.Fatalf("Expected %d reverse hits for %s in %s, got %d", expected, p, x, reverse)
+			}
+		}
+	}
+}
+
+func TestCollectionStranded(t *testing.T) {
+	c, err := gostr.NewSeqCollection([]gostr.SeqRecord{
+		{Name: "chr1", Seq: "AACCGT"},
+		{Name: "chr2", Seq: "TTGCA"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	tbls := gostr.BuildFMIndexApproxTables(c.Seq)
+	hits := []string{}
+
+	exact, err := c.FMIndexExactStrandedFromTables(tbls)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	approx, err := c.FMIndexApproxStrandedFromTables(tbls)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	exact("CAA", func(name string, offset int, strand gostr.Strand) {
+		hits = append(hits, fmt.Sprintf("%s:%d%s", name, offset, strand))
+	})
+	sort.Strings(hits)
+
+	if expected := []string{"chr2:0-"}; !reflect.DeepEqual(hits, expected) {
+		t.Errorf("Got hits %v, expected %v", hits, expected)
+	}
+
+	hits = hits[:0]
+
+	approx("AACC", 0, func(name string, offset int, strand gostr.Strand, cigar string) {
+		hits = append(hits, fmt.Sprintf("%s:%d%s:%s", name, offset, strand, cigar))
+	})
+	sort.Strings(hits)
+
+	if expected := []string{"chr1:0+:4M"}; !reflect.DeepEqual(hits, expected) {
+		t.Errorf("Got hits %v, expected %v", hits, expected)
+	}
+
+	// The reverse strand hit is written with the reverse flag and
+	// the reverse complemented read
+	var buf bytes.Buffer
+
+	sw := gostr.NewSAMWriter(&buf, c)
+	if err := sw.WriteStrandedHits(approx, 0, "r", "TGCAA", "ABCDE"); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	expected := "r\t16\tchr2\t1\t255\t5M\t*\t0\t0\tTTGCA\tEDCBA\tNM:i:0\tMD:Z:5\n"
+	if got := buf.String(); !strings.Contains(got, expected) {
+		t.Errorf("Expected the record %q in %q", expected, got)
+	}
+}
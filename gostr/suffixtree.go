@@ -3,6 +3,7 @@ package gostr
 import (
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
 	"unsafe"
 )
@@ -136,12 +137,18 @@ func (n STNode) LeafIndices(fn func(int)) {
 //     suffix tree.
 //   - w: the output stream to write the dot representation to.
 func (n STNode) ToDot(alpha *Alphabet, w io.Writer) {
+	n.toDot(alpha, w, func(v *LeafNode) string { return strconv.Itoa(v.Index) })
+}
+
+// toDot writes the subtree starting at n to w, and labels the leaves
+// with leafLabel, which must give us a valid dot label.
+func (n STNode) toDot(alpha *Alphabet, w io.Writer, leafLabel func(*LeafNode) string) {
 	switch n.NodeType {
 	case Leaf:
 		v := n.Leaf()
 		fmt.Fprintf(w, "\"%p\" -> \"%p\"[label=\"%s\"]\n",
 			v.Parent, v, v.Revmap(alpha))
-		fmt.Fprintf(w, "\"%p\"[label=%d]\n", v, v.Index)
+		fmt.Fprintf(w, "\"%p\"[label=%s]\n", v, leafLabel(v))
 
 	case Inner:
 		v := n.Inner()
@@ -161,7 +168,7 @@ func (n STNode) ToDot(alpha *Alphabet, w io.Writer) {
 
 		for _, child := range v.Children {
 			if !child.IsNil() {
-				child.ToDot(alpha, w)
+				child.toDot(alpha, w, leafLabel)
 			}
 		}
 	}
@@ -2,6 +2,7 @@ package gostr_test
 
 import (
 	"math/rand"
+	"strings"
 	"testing"
 	"time"
 
@@ -32,6 +33,39 @@ func BenchmarkMcCreight10000(b *testing.B)   { benchmarkConstruction(b, gostr.Mc
 func BenchmarkMcCreight100000(b *testing.B)  { benchmarkConstruction(b, gostr.McCreight, 100000) }
 func BenchmarkMcCreight1000000(b *testing.B) { benchmarkConstruction(b, gostr.McCreight, 1000000) }
 
+func BenchmarkSA10000(b *testing.B)   { benchmarkConstruction(b, saST, 10000) }
+func BenchmarkSA100000(b *testing.B)  { benchmarkConstruction(b, saST, 100000) }
+func BenchmarkSA1000000(b *testing.B) { benchmarkConstruction(b, saST, 1000000) }
+
+// benchmarkOnlineSearch searches in an online suffix tree for x before
+// we finish it. In a repetitive string, most of the suffixes don't
+// have leaves yet.
+func benchmarkOnlineSearch(b *testing.B, x, p string) {
+	b.Helper()
+
+	ost := gostr.NewOnlineSuffixTree(gostr.NewAlphabet(x))
+	if err := ost.AppendString(x); err != nil {
+		b.Fatalf("Unexpected error: %v", err)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		ost.Search(p, func(int) {})
+	}
+}
+
+func BenchmarkOnlineSearchRandom(b *testing.B) {
+	rng := rand.New(rand.NewSource(time.Now().UTC().UnixNano()))
+	x := testutils.RandomStringN(100000, "acgt", rng)
+	benchmarkOnlineSearch(b, x, x[50000:50010])
+}
+
+func BenchmarkOnlineSearchRepetitive(b *testing.B) {
+	x := strings.Repeat("acgt", 25000) // no suffix has a leaf beyond the first period
+	benchmarkOnlineSearch(b, x, "gtacgtacgtacgtacgtacgtacgtacgta")
+}
+
 func publicTraversal(n gostr.STNode) int {
 	switch n.NodeType {
 	case gostr.Leaf:
@@ -0,0 +1,133 @@
+package gostr
+
+// Suffix tree construction from a suffix array and an LCP array. The
+// leaves of a suffix tree, in order, are the suffixes in the suffix
+// array, and the LCP between two neighbouring suffixes is the depth of
+// the node where their paths split. So we can build the tree by adding
+// the leaves in suffix array order, keeping the path from the root to
+// the last leaf on a stack: for each new leaf, we pop the nodes that
+// are deeper than its LCP with the previous leaf, break the edge at
+// the LCP if there is no node there, and hang the leaf below it.
+// Each node is pushed and popped once, so it takes linear time, and
+// setting the suffix links afterwards is linear as well.
+
+// stFrame is a node on the path to the last leaf, and its depth.
+type stFrame struct {
+	node  *InnerNode
+	depth int
+}
+
+// SuffixTreeFromSA builds the suffix tree for x from its suffix array
+// and LCP array, with suffix links. The arrays must include the
+// sentinel suffix, as those from Sais and LcpKasai do, and the tree is
+// the same as the one McCreight builds.
+func SuffixTreeFromSA(x string, sa, lcp []int32) *SuffixTree {
+	xb, alpha := MapStringWithSentinel(x)
+	st := SuffixTree{Alpha: alpha, String: xb}
+	st.Root = st.newInner(xb[0:0])
+
+	stack := []stFrame{{node: st.Root.Inner(), depth: 0}}
+
+	var last STNode // the last leaf we added
+
+	for i, s := range sa {
+		l := int(lcp[i])
+
+		// Pop the nodes below the LCP. The last we pop is the child
+		// of the new top on the path to the last leaf.
+		child := last
+		for stack[len(stack)-1].depth > l {
+			child = wrapInner(stack[len(stack)-1].node)
+			stack = stack[:len(stack)-1]
+		}
+
+		top := stack[len(stack)-1]
+		if top.depth == l {
+			last = st.newLeaf(int(s), xb[int(s)+l:])
+			top.node.addChild(last)
+		} else {
+			// The paths split on the edge to child.
+			last = st.breakEdge(child, l-top.depth, int(s), xb[int(s)+l:])
+			stack = append(stack, stFrame{node: last.Shared().Parent, depth: l})
+		}
+	}
+
+	st.setSuffixLinks()
+
+	return &st
+}
+
+// setSuffixLinks sets the suffix links in a tree that doesn't have
+// them. If an inner node, v, has path label aα and depth d, and one
+// of its leaves is suffix i, then the node for α is the ancestor of
+// leaf i+1 at depth d-1. We collect these queries in one traversal,
+// and answer them in a second, where we keep the nodes on the path
+// from the root to the current node in an array indexed by depth, so
+// we can look up the ancestor in constant time. Both traversals are
+// linear, and so is the number of queries, one per inner node.
+func (st *SuffixTree) setSuffixLinks() {
+	type query struct {
+		node  *InnerNode
+		depth int
+	}
+
+	root := st.Root.Inner()
+	root.SuffixLink = root
+	queries := make([][]query, len(st.String))
+
+	// Returns the first leaf in the subtree.
+	var collect func(n STNode, depth int) int
+	collect = func(n STNode, depth int) int {
+		if n.NodeType == Leaf {
+			return n.Leaf().Index
+		}
+
+		v, first := n.Inner(), -1
+
+		for _, child := range v.Children {
+			if !child.IsNil() {
+				if leaf := collect(child, depth+len(child.Shared().EdgeLabel)); first < 0 {
+					first = leaf
+				}
+			}
+		}
+
+		// Inner nodes below the root never have the sentinel suffix
+		// as a leaf, so first+1 is always a suffix.
+		if v != root {
+			queries[first+1] = append(queries[first+1], query{node: v, depth: depth - 1})
+		}
+
+		return first
+	}
+
+	collect(st.Root, 0)
+
+	// atDepth[d] is the node at depth d on the path to the current
+	// node, if there is one there. Entries for depths without a node
+	// on the path can hold nodes from paths we have left, but the
+	// suffix links always go to a node on the path, and we set its
+	// entry when we enter it.
+	atDepth := make([]*InnerNode, len(st.String)+1)
+
+	var answer func(n STNode, depth int)
+	answer = func(n STNode, depth int) {
+		if n.NodeType == Leaf {
+			for _, q := range queries[n.Leaf().Index] {
+				q.node.SuffixLink = atDepth[q.depth]
+			}
+
+			return
+		}
+
+		atDepth[depth] = n.Inner()
+
+		for _, child := range n.Inner().Children {
+			if !child.IsNil() {
+				answer(child, depth+len(child.Shared().EdgeLabel))
+			}
+		}
+	}
+
+	answer(st.Root, 0)
+}
@@ -4,6 +4,7 @@ import (
 	"log"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -111,7 +112,7 @@ func Test_NaiveConstruction(t *testing.T) {
 	st := testSuffixTree(t, "NaiveST", gostr.NaiveST, x)
 	testSearchMississippi(t, "NaiveST", st)
 
-	f, err := os.Create("naive-dot.dot")
+	f, err := os.Create(filepath.Join(t.TempDir(), "naive-dot.dot"))
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -125,7 +126,7 @@ func Test_McCreightConstruction(t *testing.T) {
 	st := testSuffixTree(t, "McCreight", gostr.McCreight, x)
 	testSearchMississippi(t, "McCreight", st)
 
-	f, err := os.Create("mccreight-dot.dot")
+	f, err := os.Create(filepath.Join(t.TempDir(), "mccreight-dot.dot"))
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -135,8 +136,8 @@ func Test_McCreightConstruction(t *testing.T) {
 }
 
 func Test_STRandomStrings(t *testing.T) {
-	algos := []string{"NaiveST", "McCreight"}
-	constructors := []func(string) *gostr.SuffixTree{gostr.NaiveST, gostr.McCreight}
+	algos := []string{"NaiveST", "McCreight", "SuffixTreeFromSA"}
+	constructors := []func(string) *gostr.SuffixTree{gostr.NaiveST, gostr.McCreight, saST}
 
 	seed := time.Now().UTC().UnixNano()
 	t.Logf("Random seed: %d", seed)
@@ -154,3 +155,96 @@ func Test_STRandomStrings(t *testing.T) {
 		}
 	}
 }
+
+func saST(x string) *gostr.SuffixTree {
+	sa := gostr.Sais(x)
+	return gostr.SuffixTreeFromSA(x, sa, gostr.LcpKasai(x, sa))
+}
+
+// checkSameTree checks that two suffix trees for the same string have
+// the same shape, edge labels and leaves.
+func checkSameTree(t *testing.T, algo string, st, expected *gostr.SuffixTree, a, b gostr.STNode) {
+	t.Helper()
+
+	if a.NodeType != b.NodeType ||
+		a.Shared().Revmap(st.Alpha) != b.Shared().Revmap(expected.Alpha) ||
+		(a.NodeType == gostr.Leaf && a.Leaf().Index != b.Leaf().Index) {
+		t.Fatalf(`%s("%s"): node "%s" should be "%s".`,
+			algo, st.Alpha.RevmapBytes(st.String),
+			a.PathLabel(st.Alpha), b.PathLabel(expected.Alpha))
+	}
+
+	if a.NodeType == gostr.Inner {
+		for i, child := range a.Inner().Children {
+			other := b.Inner().Children[i]
+			if child.IsNil() != other.IsNil() {
+				t.Fatalf(`%s("%s"): node "%s" has the wrong children.`,
+					algo, st.Alpha.RevmapBytes(st.String), a.PathLabel(st.Alpha))
+			}
+
+			if !child.IsNil() {
+				checkSameTree(t, algo, st, expected, child, other)
+			}
+		}
+	}
+}
+
+// innerPathLabel is PathLabel for an inner node we only have a
+// pointer to.
+func innerPathLabel(alpha *gostr.Alphabet, v *gostr.InnerNode) string {
+	label := ""
+	for ; v != nil; v = v.Parent {
+		label = v.EdgeLabel.Revmap(alpha) + label
+	}
+
+	return label
+}
+
+// checkSuffixLinks checks that all inner nodes have a suffix link to
+// the node for their path label minus the first letter.
+func checkSuffixLinks(t *testing.T, algo string, st *gostr.SuffixTree, n gostr.STNode) {
+	t.Helper()
+
+	if n.IsNil() || n.NodeType != gostr.Inner {
+		return
+	}
+
+	v := n.Inner()
+	label := n.PathLabel(st.Alpha)
+
+	switch {
+	case v.SuffixLink == nil:
+		t.Errorf(`%s: node "%s" has no suffix link.`, algo, label)
+	case v.Parent == nil && v.SuffixLink != v:
+		t.Errorf(`%s: the root's suffix link should point to the root.`, algo)
+	case v.Parent != nil && innerPathLabel(st.Alpha, v.SuffixLink) != label[1:]:
+		t.Errorf(`%s: node "%s" links to "%s".`, algo, label, innerPathLabel(st.Alpha, v.SuffixLink))
+	}
+
+	for _, child := range v.Children {
+		checkSuffixLinks(t, algo, st, child)
+	}
+}
+
+func Test_SAConstruction(t *testing.T) {
+	x := "mississippi"
+	st := testSuffixTree(t, "SuffixTreeFromSA", saST, x)
+	testSearchMississippi(t, "SuffixTreeFromSA", st)
+	checkSuffixLinks(t, "SuffixTreeFromSA", st, st.Root)
+
+	for _, x := range []string{"", "a", "aaaa", "abab", "mississippi"} {
+		st, expected := saST(x), gostr.McCreight(x)
+		checkSameTree(t, "SuffixTreeFromSA", st, expected, st.Root, expected.Root)
+	}
+}
+
+func Test_SAConstructionRandom(t *testing.T) {
+	rng := testutils.NewRandomSeed(t)
+
+	testutils.GenerateTestStrings(10, 100, rng, func(x string) {
+		st, expected := saST(x), gostr.McCreight(x)
+
+		checkSameTree(t, "SuffixTreeFromSA", st, expected, st.Root, expected.Root)
+		checkSuffixLinks(t, "SuffixTreeFromSA", st, st.Root)
+	})
+}
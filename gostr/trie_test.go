@@ -3,6 +3,7 @@ package gostr_test
 import (
 	"log"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -30,7 +31,7 @@ func TestTrieToDot(t *testing.T) {
 	input := []string{"foo", "foobar", "bar", "baz"}
 	trie := gostr.BuildTrie(input)
 
-	f, err := os.Create("trie.dot")
+	f, err := os.Create(filepath.Join(t.TempDir(), "trie.dot"))
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -0,0 +1,346 @@
+package gostr
+
+import "sort"
+
+// Ukkonen's online suffix tree construction. Where NaiveST and
+// McCreight need the whole string before they start, Ukkonen's
+// algorithm extends the tree for x to the tree for xa, one letter at a
+// time, in amortised constant time per letter. Until we add the
+// sentinel, the tree is implicit: a suffix that is also a prefix of
+// another suffix doesn't have a leaf, but ends somewhere inside the
+// tree, and the longest of those is the active point, where we start
+// the next extension.
+//
+// The edges to leaves always run to the end of the string, so we don't
+// update their labels when we add a letter. We only remember where the
+// labels start, and fix the labels when you ask for the tree.
+
+// OnlineSuffixTree builds a suffix tree one letter at a time.
+type OnlineSuffixTree struct {
+	st       SuffixTree
+	finished bool
+
+	// The leaves, indexed by suffix, and where their edge labels start
+	leaves    []*LeafNode
+	leafStart []int
+
+	// The active point is activeLength letters down the edge out of
+	// activeNode that starts with String[activeEdge]. The remainder is
+	// the number of suffixes that we still have to insert explicitly.
+	activeNode   *InnerNode
+	activeEdge   int
+	activeLength int
+	remainder    int
+
+	// A leaf below each inner node, so we can find a suffix that
+	// starts with the string at the active point.
+	below map[*InnerNode]int
+}
+
+// NewOnlineSuffixTree returns an empty suffix tree that you can add
+// letters from alpha to.
+func NewOnlineSuffixTree(alpha *Alphabet) *OnlineSuffixTree {
+	ost := &OnlineSuffixTree{
+		st:    SuffixTree{Alpha: alpha, String: []byte{}},
+		below: map[*InnerNode]int{},
+	}
+	ost.st.Root = ost.st.newInner(ost.st.String[0:0])
+
+	root := ost.st.Root.Inner()
+	root.SuffixLink = root
+	ost.activeNode = root
+
+	return ost
+}
+
+// edgeLen returns the length of the edge to n.
+func (ost *OnlineSuffixTree) edgeLen(n STNode) int {
+	if n.NodeType == Leaf {
+		return len(ost.st.String) - ost.leafStart[n.Leaf().Index]
+	}
+
+	return len(n.Shared().EdgeLabel)
+}
+
+// edgeLetter returns the i'th letter on the edge to n.
+func (ost *OnlineSuffixTree) edgeLetter(n STNode, i int) byte {
+	if n.NodeType == Leaf {
+		return ost.st.String[ost.leafStart[n.Leaf().Index]+i]
+	}
+
+	return n.Shared().EdgeLabel[i]
+}
+
+// addLeaf adds the leaf for the next suffix below v, with an edge that
+// starts with the last letter in the string.
+func (ost *OnlineSuffixTree) addLeaf(v *InnerNode) {
+	i := len(ost.st.String) - 1
+	leaf := ost.st.newLeaf(len(ost.leaves), ost.st.String[i:])
+	v.addChild(leaf)
+
+	ost.leaves = append(ost.leaves, leaf.Leaf())
+	ost.leafStart = append(ost.leafStart, i)
+}
+
+// split breaks the edge to n after depth letters and returns the new
+// node.
+func (ost *OnlineSuffixTree) split(n STNode, depth int) *InnerNode {
+	var label EdgeLabel
+
+	if n.NodeType == Leaf {
+		start := ost.leafStart[n.Leaf().Index]
+		label = ost.st.String[start : start+depth]
+		ost.leafStart[n.Leaf().Index] += depth
+		n.Shared().EdgeLabel = ost.st.String[start+depth:]
+	} else {
+		label = n.Shared().EdgeLabel[:depth]
+		n.Shared().EdgeLabel = n.Shared().EdgeLabel[depth:]
+	}
+
+	v := ost.st.newInner(label)
+	n.Shared().Parent.addChild(v)
+	v.Inner().addChild(n)
+
+	return v.Inner()
+}
+
+// extend adds the mapped letter a to the tree.
+func (ost *OnlineSuffixTree) extend(a byte) {
+	ost.st.String = append(ost.st.String, a)
+	i := len(ost.st.String) - 1
+	root := ost.st.Root.Inner()
+
+	ost.remainder++
+
+	// The last inner node we made, which needs a suffix link to the
+	// node we make, or reach, in the next extension.
+	var lastNew *InnerNode
+
+	link := func(v *InnerNode) {
+		if lastNew != nil {
+			lastNew.SuffixLink = v
+		}
+
+		lastNew = nil
+	}
+
+	for ost.remainder > 0 {
+		if ost.activeLength == 0 {
+			ost.activeEdge = i
+		}
+
+		next := ost.activeNode.Children[ost.st.String[ost.activeEdge]]
+
+		switch {
+		case next.IsNil():
+			// The suffix ends at a node, without the letter
+			ost.addLeaf(ost.activeNode)
+			link(ost.activeNode)
+
+		case ost.activeLength >= ost.edgeLen(next):
+			// The active point is below next, so we move it down
+			ost.activeEdge += ost.edgeLen(next)
+			ost.activeLength -= ost.edgeLen(next)
+			ost.activeNode = next.Inner()
+
+			continue
+
+		case ost.edgeLetter(next, ost.activeLength) == a:
+			// The suffix is already in the tree, and so are all the
+			// shorter suffixes, so we are done with this letter.
+			link(ost.activeNode)
+			ost.activeLength++
+
+			return
+
+		default:
+			// The suffix ends on the edge, without the letter
+			v := ost.split(next, ost.activeLength)
+			ost.below[v] = len(ost.leaves)
+			ost.addLeaf(v)
+			link(v)
+			lastNew = v
+		}
+
+		ost.remainder--
+
+		if ost.activeNode == root && ost.activeLength > 0 {
+			ost.activeLength--
+			ost.activeEdge = i - ost.remainder + 1
+		} else if ost.activeNode != root {
+			ost.activeNode = ost.activeNode.SuffixLink
+		}
+	}
+}
+
+// Append adds the letter a to the end of the string. If a isn't in the
+// alphabet, or you have already finished the tree, you get an error
+// and the tree doesn't change.
+func (ost *OnlineSuffixTree) Append(a byte) error {
+	if ost.finished {
+		return NewFinishedSuffixTree()
+	}
+
+	b := ost.st.Alpha._map[a]
+	if b == Sentinel {
+		return &AlphabetLookupError{a}
+	}
+
+	ost.extend(b)
+
+	return nil
+}
+
+// AppendString adds the letters in x to the end of the string. If one
+// of them isn't in the alphabet, we add the letters before it, and
+// return an error.
+func (ost *OnlineSuffixTree) AppendString(x string) error {
+	for i := 0; i < len(x); i++ {
+		if err := ost.Append(x[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Len returns the number of letters in the tree, without the sentinel.
+func (ost *OnlineSuffixTree) Len() int {
+	if ost.finished {
+		return len(ost.st.String) - 1
+	}
+
+	return len(ost.st.String)
+}
+
+// Tree returns the suffix tree for the string so far. Before you
+// finish the tree, it is implicit, so the suffixes that are prefixes
+// of other suffixes don't have leaves. The tree shares its nodes with
+// the online tree, so it changes when you append more letters.
+func (ost *OnlineSuffixTree) Tree() *SuffixTree {
+	for i, leaf := range ost.leaves {
+		leaf.EdgeLabel = ost.st.String[ost.leafStart[i]:]
+	}
+
+	return &ost.st
+}
+
+// activeSuffix returns a suffix, with a leaf, that starts with the
+// string at the active point, i.e., with the implicit suffixes.
+func (ost *OnlineSuffixTree) activeSuffix() int {
+	if ost.activeLength == 0 {
+		return ost.below[ost.activeNode]
+	}
+
+	next := ost.activeNode.Children[ost.st.String[ost.activeEdge]]
+	if next.NodeType == Leaf {
+		return next.Leaf().Index
+	}
+
+	return ost.below[next.Inner()]
+}
+
+// Search maps visitor through the occurrences of p in the string so
+// far. The leaves below where we find p are the occurrences of
+// suffixes with leaves. The suffixes without leaves are the suffixes
+// of s = x[L:], where L is the number of leaves, and s is the string
+// at the active point, so it also occurs at a suffix i0 < L with a
+// leaf. An occurrence at L+o, inside s, is then also an occurrence at
+// i0+o, which is earlier, so we get the occurrences inside s by
+// shifting the earlier ones, from the left. We sort the occurrences
+// we shift, so the search takes O(m + occ log occ) time, however many
+// suffixes don't have leaves yet.
+func (ost *OnlineSuffixTree) Search(p string, visitor func(int)) {
+	pb, err := ost.st.Alpha.MapToBytes(p)
+	if err != nil {
+		return // we can't map, so no hits
+	}
+
+	if len(pb) == 0 {
+		// The empty string occurs at every position, including the end,
+		// as in the finished tree, where it has the sentinel's leaf.
+		for i := 0; i <= ost.Len(); i++ {
+			visitor(i)
+		}
+
+		return
+	}
+
+	// We can't use sscan, since before we finish, a pattern can run
+	// past the end of a leaf.
+	n, y := ost.st.Root, pb
+
+	for len(y) > 0 {
+		if n.NodeType == Leaf {
+			return
+		}
+
+		v := n.Inner().Children[y[0]]
+		if v.IsNil() {
+			return
+		}
+
+		k := smallest(ost.edgeLen(v), len(y))
+		for i := 1; i < k; i++ {
+			if ost.edgeLetter(v, i) != y[i] {
+				return
+			}
+		}
+
+		n, y = v, y[k:]
+	}
+
+	if ost.remainder == 0 {
+		n.LeafIndices(visitor) // all the suffixes have leaves
+		return
+	}
+
+	// The occurrences of p inside s start in [L, L+r-m] and come
+	// from those in [i0, i0+r-m].
+	r, m := ost.remainder, len(pb)
+	i0 := ost.activeSuffix()
+	shift := len(ost.leaves) - i0
+	window := []int{}
+
+	n.LeafIndices(func(i int) {
+		visitor(i)
+
+		if i0 <= i && i <= i0+r-m {
+			window = append(window, i)
+		}
+	})
+
+	sort.Ints(window)
+
+	// The shifted occurrences are larger than the ones from the
+	// leaves, so the window stays sorted as we add them.
+	for k := 0; k < len(window); k++ {
+		j := window[k] + shift
+		visitor(j)
+
+		if j <= i0+r-m {
+			window = append(window, j)
+		}
+	}
+}
+
+// Finish adds the sentinel, so all the suffixes get leaves, and
+// returns the suffix tree. It is the same tree as McCreight builds.
+// After that, you can still search in it, but you cannot add more
+// letters.
+func (ost *OnlineSuffixTree) Finish() *SuffixTree {
+	if !ost.finished {
+		ost.extend(Sentinel)
+		ost.finished = true
+	}
+
+	return ost.Tree()
+}
+
+// Ukkonen constructs a suffix tree using Ukkonen's algorithm.
+func Ukkonen(x string) *SuffixTree {
+	ost := NewOnlineSuffixTree(NewAlphabet(x))
+	checkError(ost.AppendString(x)) // x is in its own alphabet
+
+	return ost.Finish()
+}
@@ -0,0 +1,160 @@
+package gostr_test
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/mailund/gostr/gostr"
+	"github.com/mailund/gostr/testutils"
+)
+
+func Test_UkkonenConstruction(t *testing.T) {
+	x := "mississippi"
+	st := testSuffixTree(t, "Ukkonen", gostr.Ukkonen, x)
+	testSearchMississippi(t, "Ukkonen", st)
+	checkSuffixLinks(t, "Ukkonen", st, st.Root)
+
+	for _, x := range []string{"", "a", "aaaa", "abab", "abcabxabcd", "mississippi"} {
+		st, expected := gostr.Ukkonen(x), gostr.McCreight(x)
+		checkSameTree(t, "Ukkonen", st, expected, st.Root, expected.Root)
+	}
+}
+
+func Test_UkkonenRandom(t *testing.T) {
+	rng := testutils.NewRandomSeed(t)
+
+	testutils.GenerateTestStrings(10, 100, rng, func(x string) {
+		st, expected := gostr.Ukkonen(x), gostr.McCreight(x)
+
+		checkSameTree(t, "Ukkonen", st, expected, st.Root, expected.Root)
+		checkSuffixLinks(t, "Ukkonen", st, st.Root)
+	})
+}
+
+// Between appends, searching in the online tree finds the same as
+// searching in the prefix we have added so far.
+func Test_UkkonenOnlineSearch(t *testing.T) {
+	rng := testutils.NewRandomSeed(t)
+
+	for n := 0; n < 20; n++ {
+		x := testutils.RandomStringRange(1, 50, "acg", rng)
+		ost := gostr.NewOnlineSuffixTree(gostr.NewAlphabet(x))
+
+		for i := 0; i < len(x); i++ {
+			if err := ost.Append(x[i]); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			prefix := x[:i+1]
+			start := rng.Intn(len(prefix))
+			p := prefix[start : start+1+rng.Intn(len(prefix)-start)]
+
+			if rng.Intn(2) == 0 {
+				p = testutils.RandomStringRange(1, 4, "acg", rng) // one that might not be there
+			}
+
+			hits, expected := []int{}, []int{}
+			ost.Search(p, func(j int) { hits = append(hits, j) })
+			gostr.Naive(prefix, p, func(j int) { expected = append(expected, j) })
+			sort.Ints(hits)
+
+			if !reflect.DeepEqual(hits, expected) {
+				t.Fatalf("Searching for %q in %q found %v, expected %v", p, prefix, hits, expected)
+			}
+		}
+
+		checkEmptyPattern(t, ost)
+
+		st := ost.Finish()
+		checkEmptyPattern(t, ost)
+
+		if expected := gostr.McCreight(x); ost.Len() != len(x) {
+			t.Errorf("Expected length %d, got %d", len(x), ost.Len())
+		} else {
+			checkSameTree(t, "Online Ukkonen", st, expected, st.Root, expected.Root)
+		}
+	}
+}
+
+// The empty pattern occurs at every position in the string, including
+// the end, both before and after we finish the tree.
+func checkEmptyPattern(t *testing.T, ost *gostr.OnlineSuffixTree) {
+	t.Helper()
+
+	hits, expected := []int{}, []int{}
+	ost.Search("", func(j int) { hits = append(hits, j) })
+	sort.Ints(hits)
+
+	for j := 0; j <= ost.Len(); j++ {
+		expected = append(expected, j)
+	}
+
+	if !reflect.DeepEqual(hits, expected) {
+		t.Fatalf("Searching for the empty pattern found %v, expected %v", hits, expected)
+	}
+}
+
+// In repetitive strings, most of the suffixes don't have leaves until
+// we finish the tree, so the search must find them from the ones that
+// do.
+func Test_UkkonenOnlineSearchRepetitive(t *testing.T) {
+	xs := []string{
+		testutils.SingletonString(30, 'a'),
+		testutils.FibonacciString(8),
+		strings.Repeat("abc", 10),
+		strings.Repeat("aab", 10) + "b",
+	}
+
+	for _, x := range xs {
+		ost := gostr.NewOnlineSuffixTree(gostr.NewAlphabet(x))
+
+		for i := 0; i < len(x); i++ {
+			if err := ost.Append(x[i]); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			prefix := x[:i+1]
+
+			for start := 0; start < len(prefix); start++ {
+				for end := start + 1; end <= len(prefix) && end-start <= 5; end++ {
+					p := prefix[start:end]
+
+					hits, expected := []int{}, []int{}
+					ost.Search(p, func(j int) { hits = append(hits, j) })
+					gostr.Naive(prefix, p, func(j int) { expected = append(expected, j) })
+					sort.Ints(hits)
+
+					if !reflect.DeepEqual(hits, expected) {
+						t.Fatalf("Searching for %q in %q found %v, expected %v", p, prefix, hits, expected)
+					}
+				}
+			}
+		}
+	}
+}
+
+func Test_UkkonenErrors(t *testing.T) {
+	ost := gostr.NewOnlineSuffixTree(gostr.NewAlphabet("ab"))
+
+	var lookupErr *gostr.AlphabetLookupError
+	if err := ost.AppendString("abc"); !errors.As(err, &lookupErr) {
+		t.Errorf("Expected a lookup error, got %v", err)
+	}
+
+	if err := ost.Append(0); !errors.As(err, &lookupErr) {
+		t.Errorf("Expected a lookup error for the sentinel, got %v", err)
+	}
+
+	if ost.Len() != 2 {
+		t.Errorf("Expected the letters before the error in the tree, got %d", ost.Len())
+	}
+
+	ost.Finish()
+
+	if err := ost.Append('a'); !errors.Is(err, gostr.NewFinishedSuffixTree()) {
+		t.Errorf("Expected an error after finishing the tree, got %v", err)
+	}
+}
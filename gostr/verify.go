@@ -0,0 +1,301 @@
+package gostr
+
+// Verification of candidate hits. Filters, like exact seeds from
+// FMIndexExactFromTables or shared q-grams, give us regions of the
+// text where the pattern might occur, and we verify them by computing
+// the edit distance from p to the best substring of the region. We use
+// Ukkonen's cut-off: in each column of the dynamic programming table
+// we only compute rows up to one past the last row with at most k
+// edits, since no row below that can get back under k. For a region
+// around a real hit, that is O(km) work instead of O(m^2), and
+// regions that don't hold a hit die out quickly.
+
+// Verifier verifies candidate hits. It reuses its table between
+// verifications, so, as with an Aligner, it is cheaper to verify many
+// candidates with one verifier, but you cannot use it from more than
+// one goroutine at a time.
+type Verifier struct {
+	m   int // the length of p, i.e., the column length minus one
+	tab []int
+}
+
+// NewVerifier returns a new verifier.
+func NewVerifier() *Verifier {
+	return &Verifier{}
+}
+
+func (v *Verifier) idx(i, j int) int {
+	return i*(v.m+1) + j
+}
+
+func (v *Verifier) resize(n, m int) {
+	v.m = m
+
+	if size := (n + 1) * (m + 1); cap(v.tab) < size {
+		v.tab = make([]int, size)
+	} else {
+		v.tab = v.tab[:size]
+	}
+}
+
+// fill fills in the table for p against w, with entry (i,j) holding
+// the fewest edits in an alignment of p[:j] against a suffix of w[:i],
+// or k+1 if that is more than k. It returns the first i where all of
+// p aligns with the fewest edits, or -1 if none of them have at most
+// k edits.
+func (v *Verifier) fill(w, p string, k int) (end int) {
+	v.resize(len(w), len(p))
+
+	m, end := len(p), -1
+
+	// The first column, where we can only insert.
+	last := smallest(k, m) // the last row with at most k edits
+	for j := 0; j <= m; j++ {
+		v.tab[v.idx(0, j)] = smallest(j, k+1)
+	}
+
+	if last == m {
+		end = 0
+	}
+
+	for i := 1; i <= len(w); i++ {
+		v.tab[v.idx(i, 0)] = 0
+
+		// We only need the rows up to one past the last row in the
+		// previous column; the rest have more than k edits.
+		rows := smallest(last+1, m)
+
+		for j := 1; j <= rows; j++ {
+			cost := 0
+			if w[i-1] != p[j-1] {
+				cost = 1
+			}
+
+			v.tab[v.idx(i, j)] = smallest(
+				v.tab[v.idx(i-1, j-1)]+cost,
+				v.tab[v.idx(i, j-1)]+1,
+				v.tab[v.idx(i-1, j)]+1,
+				k+1,
+			)
+		}
+
+		for j := rows + 1; j <= m; j++ {
+			v.tab[v.idx(i, j)] = k + 1
+		}
+
+		last = rows
+		for last > 0 && v.tab[v.idx(i, last)] > k {
+			last--
+		}
+
+		if last == m && (end < 0 || v.tab[v.idx(i, m)] < v.tab[v.idx(end, m)]) {
+			end = i
+		}
+	}
+
+	return end
+}
+
+// traceback finds an optimal alignment of p that ends after w[:i]
+// and returns where in w it starts. It prefers matches over deletions
+// and deletions over insertions, so it places gaps as far towards the
+// start of the alignment as it can.
+func (v *Verifier) traceback(w, p string, i int) (start int, cigar string) {
+	ops := EditOps{}
+
+	for j := len(p); j > 0; {
+		d := v.tab[v.idx(i, j)]
+
+		switch {
+		case i > 0 && w[i-1] == p[j-1] && d == v.tab[v.idx(i-1, j-1)],
+			i > 0 && w[i-1] != p[j-1] && d == v.tab[v.idx(i-1, j-1)]+1:
+			ops = append(ops, Match)
+			i, j = i-1, j-1
+
+		case i > 0 && d == v.tab[v.idx(i-1, j)]+1:
+			ops = append(ops, Delete)
+			i--
+
+		default:
+			ops = append(ops, Insert)
+			j--
+		}
+	}
+
+	return i, OpsToCigar(revOps(&ops))
+}
+
+// Verify checks if p occurs with at most k edits in x[start:end]. If
+// it does, it returns a hit with the fewest edits, true, and if there
+// are several of those, the one that ends first. If it doesn't, it
+// returns false. The region is clipped to x, and the hit's position is
+// in x, not in the region.
+func (v *Verifier) Verify(x, p string, start, end, k int) (ApproxHit, bool) {
+	start, end = clipRegion(len(x), start, end)
+	if k < 0 || start > end {
+		return ApproxHit{}, false
+	}
+
+	w := x[start:end]
+
+	i := v.fill(w, p, k)
+	if i < 0 {
+		return ApproxHit{}, false
+	}
+
+	pos, cigar := v.traceback(w, p, i)
+
+	return ApproxHit{Pos: start + pos, Cigar: cigar, Edits: v.tab[v.idx(i, len(p))]}, true
+}
+
+// VerifyAt verifies a candidate hit of p at pos, the way a seed on the
+// diagonal pos suggests. With k edits, the hit can start up to k
+// positions earlier or later, and it can be k letters longer, so we
+// verify the region from pos-k to pos+len(p)+k.
+func (v *Verifier) VerifyAt(x, p string, pos, k int) (ApproxHit, bool) {
+	return v.Verify(x, p, pos-k, pos+len(p)+k, k)
+}
+
+// VerifyCandidates verifies candidate positions for p, as VerifyAt,
+// and returns the best hit at each position, as BestPerPosition. Nearby
+// candidates often verify to the same hit, and we only report it
+// once.
+func VerifyCandidates(x, p string, k int, candidates []int) []ApproxHit {
+	v := NewVerifier()
+	hits := []ApproxHit{}
+
+	for _, pos := range candidates {
+		if hit, ok := v.VerifyAt(x, p, pos, k); ok {
+			hits = append(hits, hit)
+		}
+	}
+
+	return BestPerPosition(hits)
+}
+
+// VerifyHit checks if p occurs with at most k edits in x[start:end].
+// See Verifier.Verify.
+func VerifyHit(x, p string, start, end, k int) (ApproxHit, bool) {
+	return NewVerifier().Verify(x, p, start, end, k)
+}
+
+// clipRegion clips the region from start to end to a string of length
+// n.
+func clipRegion(n, start, end int) (clippedStart, clippedEnd int) {
+	if start < 0 {
+		start = 0
+	}
+
+	if end > n {
+		end = n
+	}
+
+	return start, end
+}
+
+// VerifyBand checks if p occurs with at most k edits in x, with an
+// alignment that stays between the diagonals lo and hi, i.e., where
+// p[j] is only ever aligned to x[i] if lo <= i-j <= hi. That is the
+// alignment we want when seeds put p on a few nearby diagonals, and
+// the table only holds the band, so it uses O((hi-lo)m) time and
+// memory no matter how long x is. The hit is chosen as in Verify.
+func (v *Verifier) VerifyBand(x, p string, lo, hi, k int) (ApproxHit, bool) {
+	return v.verifyBand(x, p, lo, hi, k, lo)
+}
+
+// verifyBand is VerifyBand, except that if several alignments have the
+// fewest edits, we pick the one that ends on the diagonal closest to
+// centre, and of those the one that ends first. With centre lo, that
+// is the hit VerifyBand wants.
+func (v *Verifier) verifyBand(x, p string, lo, hi, k, centre int) (ApproxHit, bool) {
+	if k < 0 || lo > hi {
+		return ApproxHit{}, false
+	}
+
+	// We store the band with the diagonals as columns, so entry
+	// (d-lo, j) is the cell for p[:j] and x[:j+d].
+	n, m := len(x), len(p)
+	v.resize(hi-lo, m)
+
+	cell := func(d, j int) *int { return &v.tab[v.idx(d-lo, j)] }
+	inText := func(t int) bool { return 0 <= t && t <= n }
+
+	for j := 0; j <= m; j++ {
+		for d := lo; d <= hi; d++ {
+			t, val := j+d, k+1
+
+			switch {
+			case !inText(t):
+				// outside the text, so no alignment ends here
+			case j == 0:
+				val = 0
+			default:
+				if t > 0 {
+					cost := 0
+					if x[t-1] != p[j-1] {
+						cost = 1
+					}
+
+					val = smallest(val, *cell(d, j-1)+cost)
+				}
+
+				if d < hi {
+					val = smallest(val, *cell(d+1, j-1)+1)
+				}
+
+				if d > lo && t > 0 {
+					val = smallest(val, *cell(d-1, j)+1)
+				}
+			}
+
+			*cell(d, j) = val
+		}
+	}
+
+	// The alignment with the fewest edits that ends closest to centre.
+	// The last row holds k+1 outside the text, so we only find ends
+	// inside it.
+	end := lo
+	dist := func(d int) int {
+		if d < centre {
+			return centre - d
+		}
+
+		return d - centre
+	}
+
+	for d := lo + 1; d <= hi; d++ {
+		if c := *cell(d, m); c < *cell(end, m) || (c == *cell(end, m) && dist(d) < dist(end)) {
+			end = d
+		}
+	}
+
+	if *cell(end, m) > k {
+		return ApproxHit{}, false
+	}
+
+	edits := *cell(end, m)
+	ops := EditOps{}
+
+	d := end
+	for j := m; j > 0; {
+		t, val := j+d, *cell(d, j)
+
+		switch {
+		case t > 0 && x[t-1] == p[j-1] && val == *cell(d, j-1),
+			t > 0 && x[t-1] != p[j-1] && val == *cell(d, j-1)+1:
+			ops = append(ops, Match)
+			j--
+
+		case d > lo && t > 0 && val == *cell(d-1, j)+1:
+			ops = append(ops, Delete)
+			d--
+
+		default:
+			ops = append(ops, Insert)
+			j, d = j-1, d+1
+		}
+	}
+
+	return ApproxHit{Pos: d, Cigar: OpsToCigar(revOps(&ops)), Edits: edits}, true
+}
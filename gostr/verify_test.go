@@ -0,0 +1,165 @@
+package gostr_test
+
+import (
+	"testing"
+
+	"github.com/mailund/gostr/gostr"
+	"github.com/mailund/gostr/testutils"
+)
+
+func TestVerifyExamples(t *testing.T) {
+	hit, ok := gostr.VerifyHit("ttacgttt", "acgt", 0, 8, 1)
+	if !ok || hit != (gostr.ApproxHit{Pos: 2, Cigar: "4M", Edits: 0}) {
+		t.Errorf("Expected an exact hit at 2, got %v (%t)", hit, ok)
+	}
+
+	hit, ok = gostr.VerifyHit("ttacttt", "acgt", 0, 7, 1)
+	if !ok || hit.Pos != 2 || hit.Edits != 1 {
+		t.Errorf("Expected a hit with one edit at 2, got %v (%t)", hit, ok)
+	}
+
+	if hit, ok := gostr.VerifyHit("ttacttt", "acgt", 0, 7, 0); ok {
+		t.Errorf("Expected no exact hit, got %v", hit)
+	}
+
+	// The hit must be inside the region
+	if hit, ok := gostr.VerifyHit("ttacgttt", "acgt", 3, 8, 0); ok {
+		t.Errorf("Expected no hit in the region, got %v", hit)
+	}
+
+	// Regions are clipped to the text
+	hit, ok = gostr.VerifyHit("acgt", "acgt", -3, 10, 0)
+	if !ok || hit.Pos != 0 || hit.Cigar != "4M" {
+		t.Errorf("Expected a hit at 0, got %v (%t)", hit, ok)
+	}
+
+	// With as many edits as letters, the empty region is a hit
+	hit, ok = gostr.VerifyHit("tttt", "ac", 2, 2, 2)
+	if !ok || hit.Pos != 2 || hit.Cigar != "2I" || hit.Edits != 2 {
+		t.Errorf("Expected two insertions at 2, got %v (%t)", hit, ok)
+	}
+}
+
+func TestVerifyRandom(t *testing.T) {
+	rng := testutils.NewRandomSeed(t)
+	verifier := gostr.NewVerifier() // reused across the verifications
+	aligner := gostr.NewAligner(gostr.EditScoring())
+
+	for n := 0; n < 200; n++ {
+		x := testutils.RandomStringRange(0, 20, "acg", rng)
+		p := testutils.RandomStringRange(0, 8, "acg", rng)
+		start := rng.Intn(len(x) + 1)
+		end := start + rng.Intn(len(x)-start+1)
+
+		for k := 0; k < 4; k++ {
+			// The semi-global alignment with the edit scoring finds the
+			// fewest edits for p in the region.
+			fewest := -aligner.SemiGlobal(x[start:end], p).Score
+
+			hit, ok := verifier.Verify(x, p, start, end, k)
+			if ok != (fewest <= k) {
+				t.Fatalf("Verifying %q in %q[%d:%d] with %d edits gave %t, but it has %d edits",
+					p, x, start, end, k, ok, fewest)
+			}
+
+			if !ok {
+				continue
+			}
+
+			if hit.Edits != fewest {
+				t.Errorf("Hit %v of %q in %q[%d:%d] should have %d edits", hit, p, x, start, end, fewest)
+			}
+
+			c, err := gostr.ParseCigar(hit.Cigar)
+			if err != nil || hit.Pos < start || hit.Pos+c.RefLength() > end || c.ReadLength() != len(p) {
+				t.Fatalf("Hit %v of %q in %q[%d:%d] is outside the region", hit, p, x, start, end)
+			}
+
+			if n, _ := gostr.CountEdits(x, p, hit.Pos, hit.Cigar); n != hit.Edits {
+				t.Errorf("Hit %v of %q in %q has %d edits", hit, p, x, n)
+			}
+		}
+	}
+}
+
+func TestVerifyBand(t *testing.T) {
+	rng := testutils.NewRandomSeed(t)
+	verifier := gostr.NewVerifier()
+
+	for n := 0; n < 200; n++ {
+		x := testutils.RandomStringRange(0, 20, "acg", rng)
+		p := testutils.RandomStringRange(0, 8, "acg", rng)
+		k := rng.Intn(4)
+
+		// With a band that covers the whole table, we get the same as
+		// when we verify all of x
+		band, bandOk := verifier.VerifyBand(x, p, -len(p), len(x), k)
+		hit, ok := gostr.VerifyHit(x, p, 0, len(x), k)
+
+		if band != hit || bandOk != ok {
+			t.Errorf("VerifyBand(%q, %q) = %v (%t), but Verify gives %v (%t)", x, p, band, bandOk, hit, ok)
+		}
+
+		// With a narrow band, we only find hits inside it
+		lo := rng.Intn(len(x)+1) - len(p)
+		hi := lo + rng.Intn(3)
+
+		if band, ok := verifier.VerifyBand(x, p, lo, hi, k); ok {
+			if n, _ := gostr.CountEdits(x, p, band.Pos, band.Cigar); n != band.Edits || n > k {
+				t.Errorf("Band hit %v of %q in %q has %d edits", band, p, x, n)
+			}
+
+			if band.Pos < lo || band.Pos > hi {
+				t.Errorf("Band hit %v of %q in %q starts outside [%d,%d]", band, p, x, lo, hi)
+			}
+		}
+	}
+}
+
+// Exact seeds from the FM-index, verified, find the same positions as
+// the approximative search, when the seeds are long enough that the
+// pigeonhole principle says one of them must match exactly.
+func TestVerifySeeds(t *testing.T) {
+	rng := testutils.NewRandomSeed(t)
+
+	for n := 0; n < 20; n++ {
+		x := testutils.RandomStringRange(20, 60, "acgt", rng)
+		p := testutils.PickRandomSubstring(x, rng)
+		k := 1
+
+		if len(p) < 2 {
+			continue
+		}
+
+		// With one edit, one of the halves of p matches exactly, and
+		// the hit is on its diagonal.
+		search := gostr.FMIndexExactFromTables(gostr.BuildFMIndexExactTables(x))
+		candidates := []int{}
+		mid := len(p) / 2
+
+		search(p[:mid], func(i int) { candidates = append(candidates, i) })
+		search(p[mid:], func(i int) { candidates = append(candidates, i-mid) })
+
+		hits := gostr.VerifyCandidates(x, p, k, candidates)
+
+		for _, hit := range hits {
+			if n, _ := gostr.CountEdits(x, p, hit.Pos, hit.Cigar); n != hit.Edits || n > k {
+				t.Errorf("Hit %v of %q in %q has %d edits", hit, p, x, n)
+			}
+		}
+
+		// Each exact occurrence gives us an exact hit, but if another
+		// exact occurrence ends earlier in the same region, it is that
+		// one.
+		found := map[int]bool{}
+		for _, hit := range hits {
+			found[hit.Pos] = hit.Edits == 0
+		}
+
+		gostr.Naive(x, p, func(i int) {
+			if !found[i] && !found[i-1] {
+				t.Errorf("Expected an exact hit of %q near %d in %q among %v", p, i, x, hits)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package gostr
+
+// WaveletMatrix is a wavelet matrix over a string of bytes. It supports
+// Rank, Select and Access in time proportional to the number of bits
+// needed for the alphabet, and it uses that many bits per letter, plus
+// the overhead of the rank structures. Unlike OTab, the space doesn't
+// grow with the alphabet size, so it works for large alphabets where
+// OTab is too large to build.
+//
+// The matrix has one level per bit in the letters, from the most
+// significant bit to the least. At each level, we store the bits of
+// the letters, then stably sort the letters so the ones with a zero bit
+// come first, and continue with the next bit on the new order.
+type WaveletMatrix struct {
+	length int
+	levels []*rankBitVector
+	zeros  []int // number of zero bits at each level
+}
+
+// NewWaveletMatrix builds a wavelet matrix over x, where all letters
+// in x are less than asize.
+func NewWaveletMatrix(x []byte, asize int) *WaveletMatrix {
+	noLevels := 1
+	for (1 << noLevels) < asize {
+		noLevels++
+	}
+
+	wm := WaveletMatrix{
+		length: len(x),
+		levels: make([]*rankBitVector, noLevels),
+		zeros:  make([]int, noLevels),
+	}
+
+	cur := make([]byte, len(x))
+	next := make([]byte, len(x))
+
+	copy(cur, x)
+
+	for l := 0; l < noLevels; l++ {
+		shift := noLevels - l - 1
+		bv := newRankBitVector(len(x), defaultBlockWords)
+
+		for i, a := range cur {
+			if (a>>shift)&1 == 1 {
+				bv.set(i)
+			}
+		}
+
+		bv.buildRanks()
+
+		// Stable partition on the bit; zeros first.
+		z := 0
+
+		for _, a := range cur {
+			if (a>>shift)&1 == 0 {
+				next[z] = a
+				z++
+			}
+		}
+
+		o := z
+
+		for _, a := range cur {
+			if (a>>shift)&1 == 1 {
+				next[o] = a
+				o++
+			}
+		}
+
+		wm.levels[l], wm.zeros[l] = bv, z
+		cur, next = next, cur
+	}
+
+	return &wm
+}
+
+// Len returns the length of the underlying string.
+func (wm *WaveletMatrix) Len() int {
+	return wm.length
+}
+
+func (wm *WaveletMatrix) bit(a byte, l int) byte {
+	return (a >> (len(wm.levels) - l - 1)) & 1
+}
+
+// Access returns the letter at index i.
+func (wm *WaveletMatrix) Access(i int) byte {
+	var a byte
+
+	for l, bv := range wm.levels {
+		a <<= 1
+
+		if bv.get(i) {
+			a |= 1
+			i = wm.zeros[l] + bv.rank(i)
+		} else {
+			i -= bv.rank(i)
+		}
+	}
+
+	return a
+}
+
+// descend maps the interval [left,right) at the first level to the
+// interval of the letters in it that are equal to a at the last level.
+func (wm *WaveletMatrix) descend(a byte, left, right int) (newLeft, newRight int) {
+	for l, bv := range wm.levels {
+		if wm.bit(a, l) == 1 {
+			left = wm.zeros[l] + bv.rank(left)
+			right = wm.zeros[l] + bv.rank(right)
+		} else {
+			left -= bv.rank(left)
+			right -= bv.rank(right)
+		}
+	}
+
+	return left, right
+}
+
+// Rank How many times do we see letter a before index i
+// in the string?
+func (wm *WaveletMatrix) Rank(a byte, i int) int {
+	left, right := wm.descend(a, 0, i)
+	return right - left
+}
+
+// Select returns the index of occurrence number k (counting from zero)
+// of letter a, or -1 if a doesn't occur that many times.
+func (wm *WaveletMatrix) Select(a byte, k int) int {
+	// Find the interval of letters equal to a at the last level
+	left, right := wm.descend(a, 0, wm.length)
+	if k < 0 || k >= right-left {
+		return -1
+	}
+
+	// Then move back up through the levels
+	i := left + k
+
+	for l := len(wm.levels) - 1; l >= 0; l-- {
+		bv := wm.levels[l]
+
+		if wm.bit(a, l) == 1 {
+			i = bv.select1(i - wm.zeros[l])
+		} else {
+			i = bv.select0(i)
+		}
+	}
+
+	return i
+}
@@ -0,0 +1,104 @@
+package gostr_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/mailund/gostr/gostr"
+	"github.com/mailund/gostr/testutils"
+)
+
+func TestWaveletMatrix(t *testing.T) {
+	rng := testutils.NewRandomSeed(t)
+
+	for _, asize := range []int{1, 2, 5, 20, 256} {
+		t.Run(fmt.Sprintf("asize=%d", asize), func(t *testing.T) {
+			x := make([]byte, 300+rng.Intn(300))
+			for i := range x {
+				x[i] = byte(rng.Intn(asize))
+			}
+
+			wm := gostr.NewWaveletMatrix(x, asize)
+			if wm.Len() != len(x) {
+				t.Fatalf("Expected length %d, got %d", len(x), wm.Len())
+			}
+
+			for i, a := range x {
+				if wm.Access(i) != a {
+					t.Fatalf("Access(%d) = %d, expected %d", i, wm.Access(i), a)
+				}
+			}
+
+			for a := 0; a < asize; a++ {
+				rank := 0
+
+				for i := 0; i <= len(x); i++ {
+					if got := wm.Rank(byte(a), i); got != rank {
+						t.Fatalf("Rank(%d,%d) = %d, expected %d", a, i, got, rank)
+					}
+
+					if i < len(x) && x[i] == byte(a) {
+						if got := wm.Select(byte(a), rank); got != i {
+							t.Fatalf("Select(%d,%d) = %d, expected %d", a, rank, got, i)
+						}
+
+						rank++
+					}
+				}
+
+				if wm.Select(byte(a), rank) != -1 {
+					t.Errorf("Select(%d,%d) should be -1", a, rank)
+				}
+			}
+		})
+	}
+}
+
+func TestWaveletSearch(t *testing.T) {
+	rng := testutils.NewRandomSeed(t)
+
+	// All the non-sentinel bytes, so the alphabet has size 256
+	allBytes := make([]byte, 255)
+	for i := range allBytes {
+		allBytes[i] = byte(i + 1)
+	}
+
+	alphabets := map[string]string{
+		"DNA":     "acgt",
+		"Protein": "ACDEFGHIKLMNPQRSTVWY",
+		"Bytes":   string(allBytes),
+	}
+
+	for name, letters := range alphabets {
+		letters := letters
+
+		t.Run(name, func(t *testing.T) {
+			for i := 0; i < 10; i++ {
+				x := testutils.RandomStringRange(100, 500, letters, rng)
+				full := gostr.FMIndexApproxFromTables(gostr.BuildFMIndexApproxTables(x))
+				wavelet := gostr.FMIndexApproxFromTables(
+					gostr.BuildFMIndexApproxTables(x, gostr.WithWaveletOTab(), gostr.WithSASampling(5)))
+
+				for j := 0; j < 10; j++ {
+					p := x[j*5 : j*5+3]
+
+					for edits := 0; edits < 2; edits++ {
+						expected, hits := []string{}, []string{}
+
+						full(p, edits, func(i int, cigar string) {
+							expected = append(expected, fmt.Sprintf("%d:%s", i, cigar))
+						})
+						wavelet(p, edits, func(i int, cigar string) {
+							hits = append(hits, fmt.Sprintf("%d:%s", i, cigar))
+						})
+
+						if !reflect.DeepEqual(expected, hits) {
+							t.Fatalf("Wavelet search for %q gave %v, expected %v", p, hits, expected)
+						}
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package reader
+
+import "fmt"
+
+// InvalidFasta is the error when a FASTA stream has a malformed record.
+type InvalidFasta struct {
+	line   int
+	reason string
+}
+
+// NewInvalidFasta creates an InvalidFasta error for the given line
+func NewInvalidFasta(line int, reason string) *InvalidFasta {
+	return &InvalidFasta{line: line, reason: reason}
+}
+
+// Line returns the line (counting from one) where we found the problem.
+func (err *InvalidFasta) Line() int {
+	return err.line
+}
+
+// Error implements the interface for errors.
+func (err *InvalidFasta) Error() string {
+	return fmt.Sprintf("invalid fasta, line %d: %s", err.line, err.reason)
+}
+
+// Is implements the Is interface for errors.
+func (err *InvalidFasta) Is(other error) bool {
+	if e, ok := other.(*InvalidFasta); ok {
+		return e.line == err.line && e.reason == err.reason
+	}
+
+	return false
+}
+
+// InvalidFastq is the error when a FASTQ stream has a malformed record.
+type InvalidFastq struct {
+	line   int
+	reason string
+}
+
+// NewInvalidFastq creates an InvalidFastq error for the given line
+func NewInvalidFastq(line int, reason string) *InvalidFastq {
+	return &InvalidFastq{line: line, reason: reason}
+}
+
+// Line returns the line (counting from one) where we found the problem.
+func (err *InvalidFastq) Line() int {
+	return err.line
+}
+
+// Error implements the interface for errors.
+func (err *InvalidFastq) Error() string {
+	return fmt.Sprintf("invalid fastq, line %d: %s", err.line, err.reason)
+}
+
+// Is implements the Is interface for errors.
+func (err *InvalidFastq) Is(other error) bool {
+	if e, ok := other.(*InvalidFastq); ok {
+		return e.line == err.line && e.reason == err.reason
+	}
+
+	return false
+}
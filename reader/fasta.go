@@ -0,0 +1,122 @@
+package reader
+
+import (
+	"io"
+	"strings"
+
+	"github.com/mailund/gostr/gostr"
+)
+
+// FastaReader reads records from a FASTA stream. Sequences can span
+// several lines, and blank lines are ignored.
+type FastaReader struct {
+	lr lineReader
+}
+
+// NewFastaReader creates a reader for the FASTA records in r. If r is
+// gzip compressed, the reader decompresses it.
+func NewFastaReader(r io.Reader) (*FastaReader, error) {
+	br, err := openInput(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FastaReader{lr: lineReader{r: br}}, nil
+}
+
+// Read returns the next record in the stream, or io.EOF when there
+// are no more records. A malformed record gives an InvalidFasta error.
+func (fr *FastaReader) Read() (*Record, error) {
+	var (
+		header string
+		err    error
+	)
+
+	for header == "" {
+		if header, err = fr.lr.next(); err != nil {
+			return nil, err
+		}
+
+		header = strings.TrimSpace(header)
+	}
+
+	if header[0] != '>' {
+		return nil, NewInvalidFasta(fr.lr.lineNo, "expected '>' at the start of a record")
+	}
+
+	rec := Record{}
+	if rec.Name, rec.Desc = splitHeader(header[1:]); rec.Name == "" {
+		return nil, NewInvalidFasta(fr.lr.lineNo, "missing record name")
+	}
+
+	var seq strings.Builder
+
+	for {
+		line, err := fr.lr.next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if strings.HasPrefix(line, ">") {
+			fr.lr.pushBack() // the header of the next record
+			break
+		}
+
+		seq.WriteString(strings.TrimSpace(line))
+	}
+
+	rec.Seq = seq.String()
+
+	return &rec, nil
+}
+
+// ReadFasta reads all the records in a FASTA stream.
+func ReadFasta(r io.Reader) ([]*Record, error) {
+	fr, err := NewFastaReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	records := []*Record{}
+
+	for {
+		rec, err := fr.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, rec)
+	}
+}
+
+// FMIndexFromFasta reads the records in a FASTA stream and builds a
+// collection of them together with FM-index tables for it. The tables
+// have an alphabet that covers all the records, and they are built
+// for approximative search, which means that you can use them for
+// exact search as well.
+func FMIndexFromFasta(r io.Reader, opts ...gostr.FMIndexOption) (*gostr.SeqCollection, *gostr.FMIndexTables, error) {
+	records, err := ReadFasta(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	seqs := make([]gostr.SeqRecord, len(records))
+	for i, rec := range records {
+		seqs[i] = gostr.SeqRecord{Name: rec.Name, Seq: rec.Seq}
+	}
+
+	c, err := gostr.NewSeqCollection(seqs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return c, gostr.BuildFMIndexApproxTables(c.Seq, opts...), nil
+}
@@ -0,0 +1,159 @@
+package reader_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/mailund/gostr/reader"
+)
+
+func gzipString(t *testing.T, s string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write([]byte(s)); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	return buf.Bytes()
+}
+
+const fasta = `>chr1 the first one
+acgt
+acg
+
+>chr2
+>chr3	tabbed description
+tt
+ga
+`
+
+var fastaRecords = []*reader.Record{ //nolint:gochecknoglobals // test data
+	{Name: "chr1", Desc: "the first one", Seq: "acgtacg"},
+	{Name: "chr2", Seq: ""},
+	{Name: "chr3", Desc: "tabbed description", Seq: "ttga"},
+}
+
+func TestReadFasta(t *testing.T) {
+	inputs := map[string][]byte{
+		"Plain":   []byte(fasta),
+		"CRLF":    []byte(strings.ReplaceAll(fasta, "\n", "\r\n")),
+		"NoFinal": []byte(strings.TrimSuffix(fasta, "\n")),
+		"Gzip":    gzipString(t, fasta),
+	}
+
+	for name, input := range inputs {
+		input := input
+
+		t.Run(name, func(t *testing.T) {
+			records, err := reader.ReadFasta(bytes.NewReader(input))
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+
+			if !reflect.DeepEqual(records, fastaRecords) {
+				t.Errorf("Got records %v, expected %v", records, fastaRecords)
+			}
+		})
+	}
+}
+
+func TestReadFastaEmpty(t *testing.T) {
+	for _, input := range []string{"", "\n\n"} {
+		records, err := reader.ReadFasta(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		if len(records) != 0 {
+			t.Errorf("Expected no records in %q, got %v", input, records)
+		}
+	}
+}
+
+func TestFastaErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		err   error
+	}{
+		{"No header", "acgt\n", reader.NewInvalidFasta(1, "expected '>' at the start of a record")},
+		{"No name", ">chr1\nacgt\n\n>\nacgt\n", reader.NewInvalidFasta(4, "missing record name")},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := reader.ReadFasta(strings.NewReader(tt.input))
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Expected error %q, got %q", tt.err, err)
+			}
+		})
+	}
+
+	err := reader.NewInvalidFasta(4, "missing record name")
+	if err.Error() != "invalid fasta, line 4: missing record name" || err.Line() != 4 {
+		t.Errorf("Unexpected error message: %s", err)
+	}
+
+	if errors.Is(err, reader.NewInvalidFasta(3, "missing record name")) {
+		t.Error("these errors should be considered different")
+	}
+
+	if _, err := reader.NewFastaReader(bytes.NewReader([]byte{0x1f, 0x8b})); err == nil {
+		t.Error("Expected an error for a broken gzip stream")
+	}
+}
+
+func TestFMIndexFromFasta(t *testing.T) {
+	c, tbls, err := reader.FMIndexFromFasta(strings.NewReader(fasta))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	hits := []string{}
+
+	c.FMIndexExactFromTables(tbls)("g", func(name string, offset int) {
+		hits = append(hits, fmt.Sprintf("%s:%d", name, offset))
+	})
+	sort.Strings(hits)
+
+	expected := []string{"chr1:2", "chr1:6", "chr3:2"}
+	if !reflect.DeepEqual(hits, expected) {
+		t.Errorf("Got hits %v, expected %v", hits, expected)
+	}
+
+	hits = hits[:0]
+
+	c.FMIndexApproxFromTables(tbls)("tgaa", 1, func(name string, offset int, cigar string) {
+		hits = append(hits, fmt.Sprintf("%s:%d:%s", name, offset, cigar))
+	})
+
+	for _, hit := range hits {
+		if !strings.HasPrefix(hit, "chr3:") {
+			t.Errorf("Hit %s should be in chr3", hit)
+		}
+	}
+
+	if _, _, err := reader.FMIndexFromFasta(strings.NewReader("acgt")); !errors.Is(err, reader.NewInvalidFasta(1, "expected '>' at the start of a record")) {
+		t.Errorf("Unexpected error: %s", err)
+	}
+
+	// The alphabet must cover all records
+	if !tbls.Alpha.Contains('t') || !tbls.Alpha.Contains('a') {
+		t.Errorf("The alphabet doesn't cover all the records")
+	}
+}
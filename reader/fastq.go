@@ -0,0 +1,122 @@
+package reader
+
+import (
+	"io"
+	"strings"
+)
+
+// FastqReader reads records from a FASTQ stream. Sequences and quality
+// strings can span several lines, and blank lines between records are
+// ignored.
+type FastqReader struct {
+	lr lineReader
+}
+
+// NewFastqReader creates a reader for the FASTQ records in r. If r is
+// gzip compressed, the reader decompresses it.
+func NewFastqReader(r io.Reader) (*FastqReader, error) {
+	br, err := openInput(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FastqReader{lr: lineReader{r: br}}, nil
+}
+
+// Read returns the next record in the stream, or io.EOF when there
+// are no more records. A malformed record gives an InvalidFastq error.
+func (fr *FastqReader) Read() (*Record, error) {
+	var (
+		header string
+		err    error
+	)
+
+	for header == "" {
+		if header, err = fr.lr.next(); err != nil {
+			return nil, err
+		}
+
+		header = strings.TrimSpace(header)
+	}
+
+	headerLine := fr.lr.lineNo
+
+	if header[0] != '@' {
+		return nil, NewInvalidFastq(headerLine, "expected '@' at the start of a record")
+	}
+
+	rec := Record{}
+	if rec.Name, rec.Desc = splitHeader(header[1:]); rec.Name == "" {
+		return nil, NewInvalidFastq(headerLine, "missing record name")
+	}
+
+	var seq, qual strings.Builder
+
+	// The sequence runs until the '+' line
+	for {
+		line, err := fr.lr.next()
+		if err == io.EOF {
+			return nil, NewInvalidFastq(headerLine, "record has no '+' line")
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if strings.HasPrefix(line, "+") {
+			if name, _ := splitHeader(line[1:]); name != "" && name != rec.Name {
+				return nil, NewInvalidFastq(fr.lr.lineNo, "'+' line doesn't match the header")
+			}
+
+			break
+		}
+
+		seq.WriteString(strings.TrimSpace(line))
+	}
+
+	// The qualities run until we have one per letter in the sequence.
+	// We cannot look for the next '@', since '@' is also a quality.
+	for qual.Len() < seq.Len() {
+		line, err := fr.lr.next()
+		if err == io.EOF {
+			return nil, NewInvalidFastq(headerLine, "record ends before its quality string")
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		qual.WriteString(strings.TrimSpace(line))
+	}
+
+	if qual.Len() > seq.Len() {
+		return nil, NewInvalidFastq(fr.lr.lineNo, "quality string is longer than the sequence")
+	}
+
+	rec.Seq, rec.Qual = seq.String(), qual.String()
+
+	return &rec, nil
+}
+
+// ReadFastq reads all the records in a FASTQ stream.
+func ReadFastq(r io.Reader) ([]*Record, error) {
+	fr, err := NewFastqReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	records := []*Record{}
+
+	for {
+		rec, err := fr.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, rec)
+	}
+}
@@ -0,0 +1,86 @@
+package reader_test
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mailund/gostr/reader"
+)
+
+const fastq = `@read1 some description
+acgt
++
+IIII
+@read2
+ac
+gt
++read2
+@I
+I#
+
+@read3
++
+`
+
+var fastqRecords = []*reader.Record{ //nolint:gochecknoglobals // test data
+	{Name: "read1", Desc: "some description", Seq: "acgt", Qual: "IIII"},
+	{Name: "read2", Seq: "acgt", Qual: "@II#"},
+	{Name: "read3", Seq: "", Qual: ""},
+}
+
+func TestReadFastq(t *testing.T) {
+	inputs := map[string][]byte{
+		"Plain": []byte(fastq),
+		"CRLF":  []byte(strings.ReplaceAll(fastq, "\n", "\r\n")),
+		"Gzip":  gzipString(t, fastq),
+	}
+
+	for name, input := range inputs {
+		input := input
+
+		t.Run(name, func(t *testing.T) {
+			records, err := reader.ReadFastq(bytes.NewReader(input))
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+
+			if !reflect.DeepEqual(records, fastqRecords) {
+				t.Errorf("Got records %v, expected %v", records, fastqRecords)
+			}
+		})
+	}
+}
+
+func TestFastqErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		err   error
+	}{
+		{"No header", "acgt\n", reader.NewInvalidFastq(1, "expected '@' at the start of a record")},
+		{"No name", "@\nacgt\n+\nIIII\n", reader.NewInvalidFastq(1, "missing record name")},
+		{"No plus", "@r1\nac\n+\nII\n@r2\nacgt\n", reader.NewInvalidFastq(5, "record has no '+' line")},
+		{"Wrong plus", "@r1\nac\n+r2\nII\n", reader.NewInvalidFastq(3, "'+' line doesn't match the header")},
+		{"Short quality", "@r1\nacgt\n+\nII\n", reader.NewInvalidFastq(1, "record ends before its quality string")},
+		{"Long quality", "@r1\nacgt\n+\nII\nIII\n", reader.NewInvalidFastq(5, "quality string is longer than the sequence")},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := reader.ReadFastq(strings.NewReader(tt.input))
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Expected error %q, got %q", tt.err, err)
+			}
+		})
+	}
+
+	err := reader.NewInvalidFastq(5, "record has no '+' line")
+	if err.Error() != "invalid fastq, line 5: record has no '+' line" || err.Line() != 5 {
+		t.Errorf("Unexpected error message: %s", err)
+	}
+}
@@ -0,0 +1,160 @@
+// Package reader streams sequence records from FASTA and FASTQ files,
+// plain or gzip compressed, so you can feed them to the gostr indices.
+package reader
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Record is a sequence record. Name is the first word of the header
+// line and Desc the rest of it. Qual holds the quality string for
+// FASTQ records and is empty for FASTA records.
+type Record struct {
+	Name string
+	Desc string
+	Seq  string
+	Qual string
+}
+
+var gzipMagic = []byte{0x1f, 0x8b} //nolint:gochecknoglobals // a constant
+
+// openInput wraps r in a buffered reader, and decompresses it if
+// it starts with the gzip magic bytes.
+func openInput(r io.Reader) (*bufio.Reader, error) {
+	br := bufio.NewReader(r)
+
+	magic, err := br.Peek(len(gzipMagic))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	if !bytes.Equal(magic, gzipMagic) {
+		return br, nil
+	}
+
+	gz, err := gzip.NewReader(br)
+	if err != nil {
+		return nil, err
+	}
+
+	return bufio.NewReader(gz), nil
+}
+
+// lineReader reads a stream line by line and keeps track of the line
+// numbers. You can push a line back so the next call to next returns
+// it again.
+type lineReader struct {
+	r      *bufio.Reader
+	lineNo int
+	line   string
+	pushed bool
+}
+
+// next returns the next line, without the line break, or io.EOF
+// when there are no more lines.
+func (lr *lineReader) next() (string, error) {
+	if lr.pushed {
+		lr.pushed = false
+		return lr.line, nil
+	}
+
+	line, err := lr.r.ReadString('\n')
+	if err == io.EOF && line == "" {
+		return "", io.EOF
+	}
+
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+
+	lr.lineNo++
+	lr.line = strings.TrimRight(line, "\r\n")
+
+	return lr.line, nil
+}
+
+// pushBack makes the next call to next return the last line again.
+func (lr *lineReader) pushBack() {
+	lr.pushed = true
+}
+
+// splitHeader splits a header line, without the leading marker,
+// into the name and the description.
+func splitHeader(header string) (name, desc string) {
+	header = strings.TrimSpace(header)
+	if i := strings.IndexAny(header, " \t"); i >= 0 {
+		return header[:i], strings.TrimSpace(header[i+1:])
+	}
+
+	return header, ""
+}
+
+// RecordReader is the interface for readers that give us one record
+// at a time, returning io.EOF when there are no more records.
+type RecordReader interface {
+	Read() (*Record, error)
+}
+
+// linesReader reads records where each non-blank line is a sequence.
+// It names the records after the line they are on.
+type linesReader struct {
+	lr lineReader
+}
+
+func (lr *linesReader) Read() (*Record, error) {
+	for {
+		line, err := lr.lr.next()
+		if err != nil {
+			return nil, err
+		}
+
+		if line = strings.TrimSpace(line); line != "" {
+			return &Record{Name: strconv.Itoa(lr.lr.lineNo), Seq: line}, nil
+		}
+	}
+}
+
+// NewRecordReader creates a reader for r that looks at the first
+// letter in the stream to decide on the format. If it is '>' we read
+// FASTA, if it is '@' we read FASTQ, and otherwise we read one sequence
+// per line, named by its line number. As for the other readers, r can
+// be gzip compressed.
+func NewRecordReader(r io.Reader) (RecordReader, error) {
+	br, err := openInput(r)
+	if err != nil {
+		return nil, err
+	}
+
+	lr := lineReader{r: br}
+
+	// Skip whitespace until the first letter, but don't consume it
+	for {
+		b, err := br.Peek(1)
+		if err == io.EOF {
+			return &linesReader{lr: lr}, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		switch b[0] {
+		case '>':
+			return &FastaReader{lr: lr}, nil
+		case '@':
+			return &FastqReader{lr: lr}, nil
+		case ' ', '\t', '\r':
+			_, _ = br.ReadByte()
+		case '\n':
+			_, _ = br.ReadByte()
+			lr.lineNo++
+		default:
+			return &linesReader{lr: lr}, nil
+		}
+	}
+}
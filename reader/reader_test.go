@@ -0,0 +1,54 @@
+package reader_test
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/mailund/gostr/reader"
+)
+
+func TestRecordReader(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []byte
+		expected []*reader.Record
+	}{
+		{"Fasta", []byte(fasta), fastaRecords},
+		{"Fastq", []byte(fastq), fastqRecords},
+		{"Gzip", gzipString(t, "\n"+fastq), fastqRecords},
+		{"Lines", []byte("\nacgt\n  \ngg\n"), []*reader.Record{{Name: "2", Seq: "acgt"}, {Name: "4", Seq: "gg"}}},
+		{"Empty", []byte{}, []*reader.Record{}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			rr, err := reader.NewRecordReader(bytes.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+
+			records := []*reader.Record{}
+
+			for {
+				rec, err := rr.Read()
+				if err == io.EOF {
+					break
+				}
+
+				if err != nil {
+					t.Fatalf("Unexpected error: %s", err)
+				}
+
+				records = append(records, rec)
+			}
+
+			if !reflect.DeepEqual(records, tt.expected) {
+				t.Errorf("Got records %v, expected %v", records, tt.expected)
+			}
+		})
+	}
+}
@@ -71,3 +71,39 @@ func CheckSuffixArray(t *testing.T, x string, sa []int32) bool {
 
 	return result
 }
+
+// CheckLcpArray checks that lcp is the longest common prefix array
+// for the suffix array sa over x: that lcp[0] is zero and lcp[i] is
+// the length of the longest common prefix of the suffixes at sa[i-1]
+// and sa[i]. Reports errors to t.
+func CheckLcpArray(t *testing.T, x string, sa, lcp []int32) bool {
+	t.Helper()
+
+	if len(lcp) != len(sa) {
+		t.Errorf("LCP array %v has length %d but the suffix array has length %d.",
+			lcp, len(lcp), len(sa))
+
+		return false
+	}
+
+	result := true
+
+	for i := range lcp {
+		expected := 0
+		if i > 0 {
+			a, b := x[sa[i-1]:], x[sa[i]:]
+			for expected < len(a) && expected < len(b) && a[expected] == b[expected] {
+				expected++
+			}
+		}
+
+		if int(lcp[i]) != expected {
+			t.Errorf("LCP array has %d at index %d, but it should be %d.",
+				lcp[i], i, expected)
+
+			result = false
+		}
+	}
+
+	return result
+}